@@ -1,13 +1,20 @@
 package checkpoint
 
 import (
+	"bufio"
 	"bytes"
+	"compress/gzip"
 	"encoding/json"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 )
 
+// GzipSuffix marks a checkpoint file which is compressed with gzip
+const GzipSuffix = ".gz"
+
 var lock sync.Mutex
 
 func check(err error) {
@@ -16,21 +23,36 @@ func check(err error) {
 	}
 }
 
+// Save writes `data` to the checkpoint file `cname` as JSON, compressed with
+// gzip if `cname` ends in GzipSuffix. If `data` embeds Metadata it is
+// stamped with FormatVersion first. The file is written next to `cname` and
+// then renamed over it, so a crash while saving never leaves a partly
+// written checkpoint
 func Save(cname string, data interface{}) {
 	lock.Lock()
 	defer lock.Unlock()
 
-	f, err := os.Create(cname)
-	check(err)
-	defer f.Close()
+	if m, ok := data.(interface{ metadata() *Metadata }); ok {
+		m.metadata().FormatVersion = FormatVersion
+	}
 
 	dataBytes, err := json.MarshalIndent(data, "", "\t")
 	check(err)
-	dataReader := bytes.NewReader(dataBytes)
-	_, err = io.Copy(f, dataReader)
-	check(err)
+
+	if strings.HasSuffix(cname, GzipSuffix) {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		_, err = zw.Write(dataBytes)
+		check(err)
+		check(zw.Close())
+		dataBytes = buf.Bytes()
+	}
+
+	check(writeAtomic(cname, dataBytes))
 }
 
+// Load reads the checkpoint file `cname` into `data`. Compressed checkpoints
+// are recognised by their contents, whatever their name
 func Load(cname string, data interface{}) {
 	lock.Lock()
 	defer lock.Unlock()
@@ -39,7 +61,44 @@ func Load(cname string, data interface{}) {
 	check(err)
 	defer f.Close()
 
-	dec := json.NewDecoder(f)
+	r := bufio.NewReader(f)
+	var src io.Reader = r
+	if magic, _ := r.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		zr, err := gzip.NewReader(r)
+		check(err)
+		defer zr.Close()
+		src = zr
+	}
+
+	dec := json.NewDecoder(src)
 	err = dec.Decode(data)
 	check(err)
 }
+
+// writeAtomic writes `data` to a temporary file in the directory of `path`
+// and renames it to `path` once it is safely on disk
+func writeAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	// fails harmlessly once the file has been renamed
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Chmod(0644); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
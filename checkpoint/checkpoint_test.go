@@ -1,7 +1,10 @@
 package checkpoint
 
 import (
+	"bytes"
 	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -19,3 +22,119 @@ func TestSave(t *testing.T) {
 		t.Errorf("Expected %s, got %s", data, s)
 	}
 }
+
+func TestSaveStampsMetadata(t *testing.T) {
+	cname := os.TempDir() + "/test.json"
+	data := &struct {
+		Metadata
+		Generations int `json:"generations"`
+	}{Metadata{GraphFingerprint: "abc"}, 3}
+
+	Save(cname, data)
+
+	var loaded Metadata
+	Load(cname, &loaded)
+
+	if loaded.FormatVersion != FormatVersion || loaded.GraphFingerprint != "abc" {
+		t.Errorf("Expected format %d and fingerprint abc, got %d and %s", FormatVersion, loaded.FormatVersion, loaded.GraphFingerprint)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		meta  Metadata
+		valid bool
+	}{
+		{Metadata{}, true},
+		{Metadata{FormatVersion: FormatVersion, GraphFingerprint: "abc"}, true},
+		{Metadata{FormatVersion: FormatVersion, GraphFingerprint: "def"}, false},
+		{Metadata{FormatVersion: FormatVersion + 1, GraphFingerprint: "abc"}, false},
+	}
+
+	for _, test := range tests {
+		if err := test.meta.Verify("abc"); (err == nil) != test.valid {
+			t.Errorf("Expected %+v to be valid: %t, got %v", test.meta, test.valid, err)
+		}
+	}
+}
+
+func TestSaveCompressed(t *testing.T) {
+	dir := t.TempDir()
+	cname := filepath.Join(dir, "test.json"+GzipSuffix)
+	data := "test data"
+
+	Save(cname, data)
+	contents, err := os.ReadFile(cname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(contents, []byte{0x1f, 0x8b}) {
+		t.Errorf("Expected a gzip file, got %q", contents)
+	}
+
+	var s string
+	Load(cname, &s)
+	if s != data {
+		t.Errorf("Expected %s, got %s", data, s)
+	}
+
+	files, _ := os.ReadDir(dir)
+	if len(files) != 1 {
+		t.Errorf("Expected only the checkpoint in %s, got %d files", dir, len(files))
+	}
+}
+
+func TestWriteRetention(t *testing.T) {
+	dir := t.TempDir()
+	policy := Policy{KeepLast: 2, KeepEvery: 5}
+
+	for generation := 0; generation <= 12; generation++ {
+		Write(dir, generation, policy, generation)
+	}
+
+	entries, err := List(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kept := make([]int, 0)
+	for _, entry := range entries {
+		kept = append(kept, entry.Generation)
+	}
+	if want := []int{0, 5, 10, 11, 12}; !reflect.DeepEqual(kept, want) {
+		t.Errorf("Expected generations %v to be kept, got %v", want, kept)
+	}
+
+	// a resumed run which writes an earlier generation keeps it
+	Write(dir, 3, policy, 3)
+	if latest, _ := Resolve(dir); latest != filepath.Join(dir, "3.json") {
+		t.Errorf("Expected the latest checkpoint to be 3.json, got %s", latest)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	dir := t.TempDir()
+	Save(filepath.Join(dir, "2.json"), 2)
+	Save(filepath.Join(dir, "10.json"), 10)
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{filepath.Join(dir, "2.json"), filepath.Join(dir, "2.json")},
+		{dir, filepath.Join(dir, "10.json")},
+	}
+	for _, test := range tests {
+		if got, err := Resolve(test.path); err != nil || got != test.want {
+			t.Errorf("Expected %s to resolve to %s, got %s and %v", test.path, test.want, got, err)
+		}
+	}
+
+	Write(dir, 4, Policy{Compress: true}, 4)
+	if got, _ := Resolve(dir); got != filepath.Join(dir, "4.json.gz") {
+		t.Errorf("Expected the latest checkpoint 4.json.gz, got %s", got)
+	}
+
+	if _, err := Resolve(t.TempDir()); err == nil {
+		t.Errorf("Expected an error for a directory without checkpoints")
+	}
+}
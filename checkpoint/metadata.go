@@ -0,0 +1,63 @@
+package checkpoint
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// FormatVersion is the version of the checkpoint format written by Save. It
+// is raised whenever a change means older versions of SAGA can't resume
+// the checkpoints written by newer ones. Checkpoints from before it was
+// recorded have version 0
+const FormatVersion = 1
+
+// Metadata describes the run a checkpoint was saved from. Optimizers embed
+// it so that it is saved alongside their state, and Save stamps it with
+// FormatVersion. Parameters are the run's parameters as given to the
+// driver, which may be left out. History records the parameters which
+// were changed when the run was resumed
+type Metadata struct {
+	FormatVersion    int             `json:"formatVersion,omitempty"`
+	SagaVersion      string          `json:"sagaVersion,omitempty"`
+	GraphPath        string          `json:"graphPath,omitempty"`
+	GraphFingerprint string          `json:"graphFingerprint,omitempty"` // see graph.Fingerprint
+	Parameters       json.RawMessage `json:"parameters,omitempty"`
+	History          []Change        `json:"history,omitempty"`
+}
+
+// A Change records a parameter of a run which was changed from one value
+// to another when the run was resumed after `Generation` generations
+type Change struct {
+	Generation int    `json:"generation"`
+	Parameter  string `json:"parameter"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+}
+
+// SetMetadata replaces the metadata saved with a checkpoint
+func (m *Metadata) SetMetadata(meta Metadata) {
+	*m = meta
+}
+
+// GetMetadata returns the metadata saved with a checkpoint
+func (m *Metadata) GetMetadata() Metadata {
+	return *m
+}
+
+func (m *Metadata) metadata() *Metadata {
+	return m
+}
+
+// Verify returns an error if the checkpoint was saved in a newer format
+// than this version of SAGA reads, or from a graph other than the one with
+// `fingerprint`. Checkpoints which don't record a fingerprint can't be
+// checked and are assumed to match
+func (m Metadata) Verify(fingerprint string) error {
+	if m.FormatVersion > FormatVersion {
+		return fmt.Errorf("checkpoint format %d is newer than format %d, which this version of SAGA reads", m.FormatVersion, FormatVersion)
+	}
+	if m.GraphFingerprint != "" && m.GraphFingerprint != fingerprint {
+		return fmt.Errorf("the checkpoint was saved from the graph %s, which doesn't match the graph given", m.GraphPath)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package checkpoint
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LatestFile is the file in a checkpoint directory which names the
+// checkpoint written last, see Resolve
+const LatestFile = "latest"
+
+// A Policy describes how an optimizer writes its checkpoints. Compress
+// gzips each checkpoint. KeepLast keeps only that many of the newest
+// checkpoints in the directory along with every checkpoint whose generation
+// is a multiple of KeepEvery. Every checkpoint is kept when KeepLast is 0
+type Policy struct {
+	Compress  bool `json:"compress,omitempty"`
+	KeepLast  int  `json:"keepLast,omitempty"`
+	KeepEvery int  `json:"keepEvery,omitempty"`
+}
+
+// Name returns the file name of the checkpoint of `generation`
+func (p Policy) Name(generation int) string {
+	name := fmt.Sprintf("%d.json", generation)
+	if p.Compress {
+		name += GzipSuffix
+	}
+	return name
+}
+
+// An Entry is a checkpoint file in a checkpoint directory
+type Entry struct {
+	Generation int
+	Path       string
+}
+
+// Write saves `data` as the checkpoint of `generation` in the directory
+// `dir`, creating it if needed. LatestFile is then pointed at it and the
+// checkpoints which `policy` no longer keeps are removed. It returns the
+// path of the checkpoint
+func Write(dir string, generation int, policy Policy, data interface{}) string {
+	check(os.MkdirAll(dir, 0755))
+
+	name := policy.Name(generation)
+	path := filepath.Join(dir, name)
+	Save(path, data)
+	check(writeAtomic(filepath.Join(dir, LatestFile), []byte(name+"\n")))
+	check(prune(dir, policy, path))
+	return path
+}
+
+// List returns the checkpoints in the directory `dir` ordered by
+// generation. Other files are ignored
+func List(dir string) ([]Entry, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0)
+	for _, file := range files {
+		name := strings.TrimSuffix(file.Name(), GzipSuffix)
+		if file.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		generation, err := strconv.Atoi(strings.TrimSuffix(name, ".json"))
+		if err != nil {
+			continue
+		}
+		entries = append(entries, Entry{generation, filepath.Join(dir, file.Name())})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Generation < entries[j].Generation
+	})
+	return entries, nil
+}
+
+// Resolve returns the checkpoint file to resume from for `path`, which is
+// either a checkpoint file or a checkpoint directory. For a directory it
+// is the checkpoint named by LatestFile or, in directories written before
+// it existed, the checkpoint of the last generation
+func Resolve(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return path, nil
+	}
+
+	if latest, err := os.ReadFile(filepath.Join(path, LatestFile)); err == nil {
+		return filepath.Join(path, strings.TrimSpace(string(latest))), nil
+	}
+
+	entries, err := List(path)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) == 0 {
+		return "", errors.New("no checkpoints in " + path)
+	}
+	return entries[len(entries)-1].Path, nil
+}
+
+// prune removes the checkpoints in `dir` which `policy` doesn't keep. The
+// checkpoint at `keep`, which was just written, is always kept even if a
+// directory reused by a resumed run holds later generations
+func prune(dir string, policy Policy, keep string) error {
+	if policy.KeepLast <= 0 {
+		return nil
+	}
+
+	entries, err := List(dir)
+	if err != nil {
+		return err
+	}
+	for i, entry := range entries {
+		recent := i >= len(entries)-policy.KeepLast
+		milestone := policy.KeepEvery > 0 && entry.Generation%policy.KeepEvery == 0
+		if recent || milestone || entry.Path == keep {
+			continue
+		}
+		if err := os.Remove(entry.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -3,11 +3,35 @@ package config
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andey-robins/magical/genetics"
+	"github.com/andey-robins/magical/graph"
+	"github.com/andey-robins/magical/optimize"
 )
 
+// DefaultFitnessCache is the size of the fitness cache of a population
+// which doesn't give one, see genetics.FitnessCache
+const DefaultFitnessCache = 10_000
+
+// UnmarshalJSON decodes a population, giving the fields which have a
+// default other than their zero value that default when they are left out
+func (p *Population) UnmarshalJSON(data []byte) error {
+	type population Population
+	decoded := population{FitnessCache: DefaultFitnessCache}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*p = Population(decoded)
+	return nil
+}
+
 func ParseConfig(configFile string) *Config {
 	file, err := os.Open(configFile)
 	if err != nil {
@@ -31,7 +55,7 @@ func ParseConfig(configFile string) *Config {
 // Validate returns an error if the config is invalid and nil if the config is valid.
 func (c *Config) Validate() error {
 
-	validCrossover := []string{"default"}
+	validCrossover := genetics.CrossoverNames()
 
 	in := func(s string, ss []string) bool {
 		for _, v := range ss {
@@ -43,12 +67,17 @@ func (c *Config) Validate() error {
 	}
 
 	populationNames := make(map[string]bool)
+	tieBreakers := make(map[string]string)
 	for _, p := range c.Populations {
 		if _, ok := populationNames[p.Name]; ok {
 			return errors.New("duplicate population name: " + p.Name)
 		}
 
 		populationNames[p.Name] = true
+		tieBreakers[p.Name] = p.TieBreaker
+		if p.TieBreaker == "" {
+			tieBreakers[p.Name] = genetics.TieBreakerNone
+		}
 
 		if p.Population < 4 || p.Population > 10_000 || p.Population%2 != 0 {
 			return errors.New("invalid population size: " + p.Name)
@@ -69,6 +98,113 @@ func (c *Config) Validate() error {
 		if p.Epsilon < 0 || p.Epsilon > 1_000_000 {
 			return errors.New("invalid epsilon: " + p.Name)
 		}
+
+		if p.TieBreaker != "" && !in(p.TieBreaker, genetics.TieBreakers()) {
+			return errors.New("invalid tie-breaker: " + p.TieBreaker)
+		}
+
+		if p.Selection != "" && !in(p.Selection, genetics.SelectionNames()) {
+			return errors.New("invalid selection strategy: " + p.Selection)
+		}
+
+		if p.TournamentSize < 0 || p.TournamentSize > p.Population {
+			return errors.New("invalid tournament size: " + p.Name)
+		}
+
+		if p.TruncationRatio < 0 || p.TruncationRatio > 1 {
+			return errors.New("invalid truncation ratio: " + p.Name)
+		}
+
+		if p.Elitism < 0 || p.Elitism >= p.Population {
+			return errors.New("invalid elitism: " + p.Name)
+		}
+
+		for op, weight := range p.MutationOperators {
+			if !in(op, genetics.MutationNames()) {
+				return errors.New("invalid mutation operator: " + op)
+			}
+			if weight < 0 {
+				return errors.New("invalid mutation operator weight: " + op)
+			}
+		}
+
+		if p.StagnationWindow < 0 || p.RestartAfter < 0 {
+			return errors.New("invalid stagnation window or restart: " + p.Name)
+		}
+
+		if p.MutationBoost < 0 || p.MaxMutation < 0 || p.MaxMutation > 1 {
+			return errors.New("invalid adaptive mutation: " + p.Name)
+		}
+
+		if p.ReseedFraction < 0 || p.ReseedFraction > 1 {
+			return errors.New("invalid reseed fraction: " + p.Name)
+		}
+
+		if p.Islands < 0 || p.MigrationInterval < 0 || p.Migrants < 0 || p.Migrants > p.Population {
+			return errors.New("invalid islands: " + p.Name)
+		}
+
+		if p.Topology != "" && !in(p.Topology, genetics.Topologies()) {
+			return errors.New("invalid migration topology: " + p.Topology)
+		}
+
+		if p.LocalSearch != "" && !in(p.LocalSearch, genetics.LocalSearchNames()) {
+			return errors.New("invalid local search: " + p.LocalSearch)
+		}
+
+		if p.LocalSearchGenes < 0 || p.LocalSearchGenes > p.Population || p.LocalSearchSteps < 0 {
+			return errors.New("invalid local search genes or steps: " + p.Name)
+		}
+
+		if p.Dedup != "" && !in(p.Dedup, genetics.DedupNames()) {
+			return errors.New("invalid dedup strategy: " + p.Dedup)
+		}
+
+		if p.FitnessCache < 0 {
+			return errors.New("invalid fitness cache size: " + p.Name)
+		}
+
+		if p.Workers < 0 {
+			return errors.New("invalid number of workers: " + p.Name)
+		}
+
+		if p.CheckpointKeep < 0 || p.CheckpointKeepEvery < 0 {
+			return errors.New("invalid checkpoint retention: " + p.Name)
+		}
+
+		if p.Algorithm != "" && !in(p.Algorithm, optimize.Algorithms()) {
+			return errors.New("invalid algorithm: " + p.Algorithm)
+		}
+
+		if p.Algorithm != "" && p.Algorithm != optimize.AlgorithmGA && p.Islands > 1 {
+			return errors.New("islands require the genetic algorithm: " + p.Name)
+		}
+
+		if p.Cooling != "" && !in(p.Cooling, optimize.CoolingSchedules()) {
+			return errors.New("invalid cooling schedule: " + p.Cooling)
+		}
+
+		if p.Temperature < 0 || p.CoolingRate < 0 || p.CoolingRate > 1 {
+			return errors.New("invalid temperature or cooling rate: " + p.Name)
+		}
+
+		if p.TabuTenure < 0 || p.TabuCandidates < 0 {
+			return errors.New("invalid tabu tenure or candidates: " + p.Name)
+		}
+
+		for _, h := range p.SeedHeuristics {
+			if !in(h, graph.HeuristicNames()) {
+				return errors.New("invalid seed heuristic: " + h)
+			}
+		}
+
+		if p.MaxGenerations < 0 || p.MaxEvaluations < 0 || p.TargetFitness < 0 {
+			return errors.New("invalid termination rules: " + p.Name)
+		}
+
+		if limit, err := p.TimeBudget(); err != nil || limit < 0 {
+			return errors.New("invalid time limit: " + p.TimeLimit)
+		}
 	}
 
 	jobNames := make(map[string]bool)
@@ -80,7 +216,51 @@ func (c *Config) Validate() error {
 		if _, ok := populationNames[j.Population]; !ok {
 			return errors.New("invalid population name: " + j.Population)
 		}
+
+		for _, island := range j.IslandPopulations {
+			if _, ok := populationNames[island]; !ok {
+				return errors.New("invalid island population name: " + island)
+			}
+			// islands are compared with each other by their tie-breaker
+			if tieBreakers[island] != tieBreakers[j.IslandPopulations[0]] {
+				return errors.New("island populations break ties differently: " + j.Name)
+			}
+		}
 	}
 
 	return nil
 }
+
+// TimeBudget parses the TimeLimit of the population, which is 0 when it
+// isn't set
+func (p *Population) TimeBudget() (time.Duration, error) {
+	if p.TimeLimit == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(p.TimeLimit)
+}
+
+// ParseWeights parses a list of weighted names such as "swap:2,insert:1"
+// into a map of names to weights. A name without a weight has weight 1
+func ParseWeights(s string) (map[string]float64, error) {
+	weights := make(map[string]float64)
+	if s == "" {
+		return weights, nil
+	}
+
+	for _, entry := range strings.Split(s, ",") {
+		name, weight, found := strings.Cut(strings.TrimSpace(entry), ":")
+		if !found {
+			weights[name] = 1
+			continue
+		}
+
+		w, err := strconv.ParseFloat(weight, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid weight for %s: %s", name, weight)
+		}
+		weights[name] = w
+	}
+
+	return weights, nil
+}
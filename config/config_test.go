@@ -1,6 +1,10 @@
 package config
 
-import "testing"
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
 
 func TestParseExampleConfig(t *testing.T) {
 	config := ParseConfig("../input/config/test.json")
@@ -17,3 +21,71 @@ func TestParseExampleConfig(t *testing.T) {
 		t.Error("expected 2 job, got", len(config.Jobs))
 	}
 }
+
+func TestParseWeights(t *testing.T) {
+	weights, err := ParseWeights("swap:2, insert:0.5,block")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]float64{"swap": 2, "insert": 0.5, "block": 1}
+	if len(weights) != len(expected) {
+		t.Errorf("expected %v, got %v", expected, weights)
+	}
+	for name, weight := range expected {
+		if weights[name] != weight {
+			t.Errorf("expected weight %v for %s, got %v", weight, name, weights[name])
+		}
+	}
+
+	if _, err := ParseWeights("swap:lots"); err == nil {
+		t.Error("expected an error for a non-numeric weight")
+	}
+}
+
+func TestValidateTermination(t *testing.T) {
+	pop := &Population{Name: "p", Population: 4, Crossover: "default", TimeLimit: "90m", MaxGenerations: 10}
+	c := &Config{Populations: []*Population{pop}}
+	if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if limit, _ := pop.TimeBudget(); limit != 90*time.Minute {
+		t.Errorf("expected a time limit of 90m, got %v", limit)
+	}
+
+	pop.TimeLimit = "an hour"
+	if err := c.Validate(); err == nil {
+		t.Error("expected an error for an unparseable time limit")
+	}
+
+	pop.TimeLimit = ""
+	pop.MaxEvaluations = -1
+	if err := c.Validate(); err == nil {
+		t.Error("expected an error for a negative evaluation budget")
+	}
+}
+
+func TestFitnessCacheDefault(t *testing.T) {
+	var populations []*Population
+	if err := json.Unmarshal([]byte(`[{"name": "default"}, {"name": "off", "fitnessCache": 0}]`), &populations); err != nil {
+		t.Fatal(err)
+	}
+	if populations[0].FitnessCache != DefaultFitnessCache || populations[1].FitnessCache != 0 {
+		t.Errorf("expected cache sizes of %d and 0, got %d and %d", DefaultFitnessCache, populations[0].FitnessCache, populations[1].FitnessCache)
+	}
+}
+
+func TestValidateIslandTieBreakers(t *testing.T) {
+	a := &Population{Name: "a", Population: 4, Crossover: "default"}
+	b := &Population{Name: "b", Population: 4, Crossover: "default", TieBreaker: "none"}
+	job := &Job{Name: "job", Population: "a", IslandPopulations: []string{"a", "b"}}
+	c := &Config{Populations: []*Population{a, b}, Jobs: []*Job{job}}
+	if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	b.TieBreaker = "area"
+	if err := c.Validate(); err == nil {
+		t.Error("expected an error for islands which break ties differently")
+	}
+}
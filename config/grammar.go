@@ -15,6 +15,61 @@ type Population struct {
 	CheckpointFreq int     `json:"checkpointFrequency"`
 	CheckpointPath string  `json:"checkpointPath"`
 	Seed           int     `json:"seed"`
+	TieBreaker     string  `json:"tieBreaker"`
+
+	// CheckpointCompress gzips checkpoints, and when CheckpointKeep is set
+	// only that many of the newest checkpoints are kept along with every
+	// CheckpointKeepEvery-th generation
+	CheckpointCompress  bool `json:"checkpointCompress"`
+	CheckpointKeep      int  `json:"checkpointKeep"`
+	CheckpointKeepEvery int  `json:"checkpointKeepEvery"`
+
+	Selection       string  `json:"selection"`
+	TournamentSize  int     `json:"tournamentSize"`
+	TruncationRatio float64 `json:"truncationRatio"`
+	Elitism         int     `json:"elitism"`
+
+	MutationOperators map[string]float64 `json:"mutationOperators"`
+
+	StagnationWindow int     `json:"stagnationWindow"`
+	MutationBoost    float64 `json:"mutationBoost"`
+	MaxMutation      float64 `json:"maxMutation"`
+	ReseedFraction   float64 `json:"reseedFraction"`
+	RestartAfter     int     `json:"restartAfter"`
+
+	Islands           int    `json:"islands"`
+	Topology          string `json:"topology"`
+	MigrationInterval int    `json:"migrationInterval"`
+	Migrants          int    `json:"migrants"`
+
+	LocalSearch      string `json:"localSearch"`
+	LocalSearchGenes int    `json:"localSearchGenes"`
+	LocalSearchSteps int    `json:"localSearchSteps"`
+
+	Dedup        string `json:"dedup"`
+	FitnessCache int    `json:"fitnessCache"` // the number of sequences, 0 to disable
+	Workers      int    `json:"workers"`      // the goroutines evaluating genes, GOMAXPROCS when 0
+
+	Algorithm      string  `json:"algorithm"`
+	Temperature    float64 `json:"temperature"`
+	Cooling        string  `json:"cooling"`
+	CoolingRate    float64 `json:"coolingRate"`
+	TabuTenure     int     `json:"tabuTenure"`
+	TabuCandidates int     `json:"tabuCandidates"`
+
+	// SeedSequences lists sequence files and SeedHeuristics lists graph
+	// heuristics which replace part of the random initial population
+	SeedSequences  []string `json:"seedSequences"`
+	SeedHeuristics []string `json:"seedHeuristics"`
+
+	// Termination rules which stop the optimizer before epsilon generations
+	// without improvement, each disabled when it is zero. TimeLimit is a
+	// duration such as "90m", see TimeBudget
+	MaxGenerations int    `json:"maxGenerations"`
+	TimeLimit      string `json:"timeLimit"`
+	MaxEvaluations int    `json:"maxEvaluations"`
+	TargetFitness  int    `json:"targetFitness"`
+	StopAtBound    bool   `json:"stopAtBound"`
 }
 
 type Job struct {
@@ -22,4 +77,12 @@ type Job struct {
 	GraphFile  string `json:"graph"`
 	OutputDir  string `json:"out"`
 	Population string `json:"population"`
+
+	// IslandPopulations optionally lists one population per island. The
+	// job's population then only describes migration, seed and checkpoints
+	IslandPopulations []string `json:"islandPopulations"`
+
+	// RunLog is an optional file which every generation is logged to, as
+	// CSV when it ends in .csv and JSON lines otherwise
+	RunLog string `json:"runlog"`
 }
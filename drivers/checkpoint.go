@@ -0,0 +1,190 @@
+package drivers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/andey-robins/magical/checkpoint"
+	"github.com/andey-robins/magical/inspect"
+	"github.com/andey-robins/magical/validation"
+)
+
+// InspectDriver summarizes the checkpoint at `path` without resuming it, or
+// prints a convergence table of every checkpoint if `path` is a checkpoint
+// directory. If `bestFpath` is given the best sequence of the checkpoint,
+// or of the latest checkpoint in the directory, is written to it
+func InspectDriver(path, bestFpath string) {
+	v := validation.NewValidator(validation.Rules{
+		validation.ValidateNonEmpty("checkpoint", path),
+	})
+	v.MustValidate()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		fmt.Printf("unable to find the checkpoint: %v\n", err)
+		os.Exit(1)
+	}
+
+	if info.IsDir() {
+		checkpoints, err := inspect.ReadDir(path)
+		if err != nil {
+			fmt.Printf("unable to read the checkpoints: %v\n", err)
+			os.Exit(1)
+		}
+		printConvergence(checkpoints)
+	} else {
+		printSummary(inspect.Read(path))
+	}
+
+	if bestFpath != "" {
+		latest, err := checkpoint.Resolve(path)
+		if err != nil {
+			fmt.Printf("unable to find the checkpoint: %v\n", err)
+			os.Exit(1)
+		}
+		c := inspect.Read(latest)
+		if c.BestGene == nil || c.BestGene.Sequence == nil {
+			fmt.Printf("%s has no best sequence\n", latest)
+			os.Exit(1)
+		}
+		if err := c.BestGene.Sequence.WriteToFile(bestFpath); err != nil {
+			fmt.Printf("unable to write the best sequence: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Wrote the best sequence of %s, with fitness %d, to %s\n", latest, c.BestFitness, bestFpath)
+	}
+}
+
+// DiffDriver compares the checkpoints at `pathA` and `pathB`, which may be
+// checkpoint directories to compare their latest checkpoints. The genes of
+// a checkpoint aren't all evaluated, so the fitness distributions are only
+// compared when the graph is given with `graphFile` or can be found from
+// the metadata of `pathB`
+func DiffDriver(pathA, pathB, graphFile string) {
+	v := validation.NewValidator(validation.Rules{
+		validation.ValidateNonEmpty("checkpoint", pathA),
+		validation.ValidateNonEmpty("checkpoint", pathB),
+	})
+	v.MustValidate()
+
+	checkpoints := make([]*inspect.Checkpoint, 2)
+	for i, path := range []string{pathA, pathB} {
+		resolved, err := checkpoint.Resolve(path)
+		if err != nil {
+			fmt.Printf("unable to find the checkpoint: %v\n", err)
+			os.Exit(1)
+		}
+		checkpoints[i] = inspect.Read(resolved)
+	}
+	a, b := checkpoints[0], checkpoints[1]
+
+	if graphFile == "" {
+		graphFile, _ = locateGraph(b.Path, b.Metadata)
+	}
+	evaluated := graphFile != ""
+	if evaluated {
+		g := loadGraphByFileType(graphFile)
+		for _, c := range checkpoints {
+			if err := c.Evaluate(g); err != nil {
+				fmt.Printf("unable to evaluate %s: %v\n", c.Path, err)
+				os.Exit(1)
+			}
+		}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "\tA\tB\n")
+	fmt.Fprintf(w, "checkpoint\t%s\t%s\n", a.Path, b.Path)
+	fmt.Fprintf(w, "algorithm\t%s\t%s\n", a.Algorithm, b.Algorithm)
+	sa, sb := a.Stats(), b.Stats()
+	fmt.Fprintf(w, "generation\t%d\t%d\n", sa.Generation, sb.Generation)
+	fmt.Fprintf(w, "best\t%d\t%d\n", sa.BestFitness, sb.BestFitness)
+	fmt.Fprintf(w, "average\t%.3f\t%.3f\n", sa.AvgFitness, sb.AvgFitness)
+	fmt.Fprintf(w, "median\t%.1f\t%.1f\n", sa.MedianFitness, sb.MedianFitness)
+	fmt.Fprintf(w, "worst\t%d\t%d\n", sa.WorstFitness, sb.WorstFitness)
+	fmt.Fprintf(w, "evaluations\t%d\t%d\n", sa.Evaluations, sb.Evaluations)
+	w.Flush()
+
+	cmp := inspect.Compare(a, b)
+	fmt.Printf("\nPopulation overlap: %d of %d and %d distinct sequences are in both\n", cmp.Shared, cmp.UniqueA, cmp.UniqueB)
+
+	if !evaluated {
+		fmt.Println("The graph of the checkpoints wasn't found, give it with -graph to compare their fitness")
+		return
+	}
+	fmt.Printf("\nFitness of the population, with graph %s\n", graphFile)
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "fitness\tA\tB\tchange\t\n")
+	for i, fitness := range cmp.Fitness {
+		fmt.Fprintf(w, "%d\t%d\t%d\t%+d\t\n", fitness, cmp.CountA[i], cmp.CountB[i], cmp.CountB[i]-cmp.CountA[i])
+	}
+	w.Flush()
+}
+
+// printSummary prints what a checkpoint records about its run
+func printSummary(c *inspect.Checkpoint) {
+	s := c.Stats()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Checkpoint:\t%s\n", c.Path)
+	fmt.Fprintf(w, "Algorithm:\t%s\n", describeAlgorithm(c))
+	fmt.Fprintf(w, "Generation:\t%d\n", s.Generation)
+	fmt.Fprintf(w, "Best fitness:\t%d\n", s.BestFitness)
+	fmt.Fprintf(w, "Population:\t%d genes, %d distinct\n", len(c.Population()), s.Diversity.Unique)
+	fmt.Fprintf(w, "Fitness:\taverage %.3f, median %.1f, worst %d\n", s.AvgFitness, s.MedianFitness, s.WorstFitness)
+	fmt.Fprintf(w, "Evaluations:\t%d\n", s.Evaluations)
+	fmt.Fprintf(w, "Seed:\t%d\n", s.Seed)
+	fmt.Fprintf(w, "Epsilon:\t%d (%d generations without improvement)\n", c.Epsilon, c.Stagnant)
+	fmt.Fprintf(w, "Stopped:\t%s\n", describeStop(s.StopReason))
+	if c.GraphPath != "" {
+		fmt.Fprintf(w, "Graph:\t%s\n", c.GraphPath)
+	}
+	if c.SagaVersion != "" {
+		fmt.Fprintf(w, "Saved by:\tSAGA %s, format %d\n", c.SagaVersion, c.FormatVersion)
+	}
+	for _, change := range c.History {
+		fmt.Fprintf(w, "Changed:\t%s from %s to %s after generation %d\n", change.Parameter, change.From, change.To, change.Generation)
+	}
+	w.Flush()
+
+	var params bytes.Buffer
+	if json.Indent(&params, c.Parameters, "  ", "  ") == nil {
+		fmt.Printf("Parameters:\n  %s\n", params.String())
+	}
+}
+
+// printConvergence prints a row for each checkpoint of a run
+func printConvergence(checkpoints []*inspect.Checkpoint) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, strings.Join([]string{"generation", "best", "average", "median", "worst", "unique", "evaluations", ""}, "\t"))
+	for _, c := range checkpoints {
+		s := c.Stats()
+		fmt.Fprintf(w, "%d\t%d\t%.3f\t%.1f\t%d\t%d\t%d\t\n", s.Generation, s.BestFitness, s.AvgFitness, s.MedianFitness, s.WorstFitness, s.Diversity.Unique, s.Evaluations)
+	}
+	w.Flush()
+
+	if len(checkpoints) > 0 {
+		last := checkpoints[len(checkpoints)-1]
+		fmt.Printf("%d checkpoints of %s, stopped: %s\n", len(checkpoints), describeAlgorithm(last), describeStop(last.StopReason))
+	}
+}
+
+// describeAlgorithm names the optimizer of a checkpoint
+func describeAlgorithm(c *inspect.Checkpoint) string {
+	if len(c.Islands) > 0 {
+		return fmt.Sprintf("%s, %d islands", c.Algorithm, len(c.Islands))
+	}
+	return c.Algorithm
+}
+
+// describeStop explains the stop reason of a checkpoint, which is only
+// recorded by the checkpoint saved once a run stopped
+func describeStop(reason string) string {
+	if reason == "" {
+		return "no"
+	}
+	return reason
+}
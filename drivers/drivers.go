@@ -5,20 +5,33 @@ package drivers
 // or dispatch work into the API
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"math"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
 	"time"
 
 	"github.com/andey-robins/magical/checkpoint"
 	"github.com/andey-robins/magical/config"
+	"github.com/andey-robins/magical/exact"
 	"github.com/andey-robins/magical/genetics"
 	"github.com/andey-robins/magical/graph"
+	"github.com/andey-robins/magical/optimize"
 	"github.com/andey-robins/magical/parsers/blif"
+	"github.com/andey-robins/magical/runlog"
 	"github.com/andey-robins/magical/sequence"
 	"github.com/andey-robins/magical/validation"
 )
 
+// Version is the version of SAGA, which is recorded in checkpoints
+const Version = "0.2.0"
+
 // VerifyDriver validates a sequence against a graph
 func VerifyDriver(graphFpath, seqFpath string) {
 	v := validation.NewValidator(validation.Rules{
@@ -39,32 +52,96 @@ func VerifyDriver(graphFpath, seqFpath string) {
 	}
 }
 
-// MinimizeDriver uses genetic algorithms to minimize the memory utilization of a sequence over a graph
-func MinimizeDriver(graphFpath, seqFpath string, popSize, epsilon, seed int, mutation float64, checkpointFreq int, chkpath string) {
+// MinimizeDriver uses genetic algorithms, or the optimizer selected by `pop.Algorithm`, to minimize the
+// memory utilization of a sequence over a graph. The parameters of the optimizer are described by `pop`
+// in the same way as a config file. Every generation is logged to `runlogFpath` if it is given
+func MinimizeDriver(graphFpath, seqFpath, runlogFpath string, pop *config.Population) {
 	v := validation.NewValidator(validation.Rules{
 		validation.ValidateNonEmpty("graph", graphFpath),
 		validation.ValidateNonEmpty("sequence", seqFpath),
-		validation.ValidateRangeInt(4, 10_000, popSize),
-		validation.ValidateRangeInt(0, 1_000_000, epsilon),
-		validation.ValidateRangeFloat(0.0, 1.0, mutation),
-		validation.ValidateRangeInt(0, math.MaxInt64, checkpointFreq),
+		validation.ValidateRangeInt(4, 10_000, pop.Population),
+		validation.ValidateRangeInt(0, 1_000_000, pop.Epsilon),
+		validation.ValidateRangeFloat(0.0, 1.0, pop.MutationRate),
+		validation.ValidateRangeInt(0, math.MaxInt64, pop.CheckpointFreq),
+		validation.ValidateRangeInt(0, math.MaxInt64, pop.CheckpointKeep),
+		validation.ValidateRangeInt(0, math.MaxInt64, pop.CheckpointKeepEvery),
+		validation.ValidateOneOf("tiebreak", pop.TieBreaker, genetics.TieBreakers()),
+		validation.ValidateOneOf("xover", pop.Crossover, genetics.CrossoverNames()),
+		validation.ValidateRangeFloat(0.0, 1.0, pop.CrossoverRate),
+		validation.ValidateOneOf("selection", pop.Selection, genetics.SelectionNames()),
+		validation.ValidateRangeInt(1, pop.Population, pop.TournamentSize),
+		validation.ValidateRangeFloat(0.0, 1.0, pop.TruncationRatio),
+		validation.ValidateRangeInt(0, pop.Population-1, pop.Elitism),
+		validation.ValidateRangeInt(0, 1_000_000, pop.StagnationWindow),
+		validation.ValidateRangeFloat(0.0, math.MaxFloat64, pop.MutationBoost),
+		validation.ValidateRangeFloat(0.0, 1.0, pop.MaxMutation),
+		validation.ValidateRangeFloat(0.0, 1.0, pop.ReseedFraction),
+		validation.ValidateRangeInt(0, 1_000_000, pop.RestartAfter),
+		validation.ValidateRangeInt(1, 1_000, pop.Islands),
+		validation.ValidateOneOf("topology", pop.Topology, genetics.Topologies()),
+		validation.ValidateRangeInt(0, 1_000_000, pop.MigrationInterval),
+		validation.ValidateRangeInt(0, pop.Population, pop.Migrants),
+		validation.ValidateOneOf("localsearch", pop.LocalSearch, genetics.LocalSearchNames()),
+		validation.ValidateRangeInt(1, pop.Population, pop.LocalSearchGenes),
+		validation.ValidateRangeInt(0, math.MaxInt64, pop.LocalSearchSteps),
+		validation.ValidateOneOf("dedup", pop.Dedup, genetics.DedupNames()),
+		validation.ValidateRangeInt(0, math.MaxInt64, pop.FitnessCache),
+		validation.ValidateRangeInt(0, math.MaxInt64, pop.Workers),
+		validation.ValidateOneOf("algorithm", pop.Algorithm, optimize.Algorithms()),
+		validation.ValidateRangeFloat(0.0, math.MaxFloat64, pop.Temperature),
+		validation.ValidateOneOf("cooling", pop.Cooling, optimize.CoolingSchedules()),
+		validation.ValidateRangeFloat(0.0, 1.0, pop.CoolingRate),
+		validation.ValidateRangeInt(0, math.MaxInt64, pop.TabuTenure),
+		validation.ValidateRangeInt(0, math.MaxInt64, pop.TabuCandidates),
+		validation.ValidateRangeInt(0, math.MaxInt64, pop.MaxGenerations),
+		validation.ValidateRangeInt(0, math.MaxInt64, pop.MaxEvaluations),
+		validation.ValidateRangeInt(0, math.MaxInt64, pop.TargetFitness),
+		func() error {
+			_, err := pop.TimeBudget()
+			return err
+		},
+		func() error {
+			if pop.Algorithm != "" && pop.Algorithm != optimize.AlgorithmGA && pop.Islands > 1 {
+				return errors.New("islands require the genetic algorithm, not " + pop.Algorithm)
+			}
+			return nil
+		},
 	})
+	for op := range pop.MutationOperators {
+		v.Add(validation.ValidateOneOf("mutators", op, genetics.MutationNames()))
+	}
+	for _, h := range pop.SeedHeuristics {
+		v.Add(validation.ValidateNonEmpty("seedheuristics", h))
+		v.Add(validation.ValidateOneOf("seedheuristics", h, graph.HeuristicNames()))
+	}
+	for _, path := range pop.SeedSequences {
+		v.Add(validation.ValidateNonEmpty("seedseq", path))
+	}
 	v.MustValidate()
 
-	if seed == 0 {
+	if pop.Seed == 0 {
 		log.Println("Using random seed")
-		seed = int(time.Now().UnixNano())
+		pop.Seed = int(time.Now().UnixNano())
 	}
 
 	g := loadGraphByFileType(graphFpath)
-	p := genetics.NewGA(popSize, epsilon, mutation, g, seed, checkpointFreq, chkpath)
-
-	p.Evolve(g)
+	p := newOptimizer(pop, nil, g)
+	p.SetMetadata(newMetadata(graphFpath, g, pop))
+	p.SeedSequences(g, loadSeeds(pop, g))
+	closeRunLog := openRunLog(p, runlogFpath)
+
+	ctx, stop := interruptContext()
+	defer stop()
+	err := p.Evolve(ctx, g)
+	reportInterrupted(ctx)
+	closeRunLog()
+	exitOnError(err)
 
 	fit, seq := p.GetBest(g)
 
-	fmt.Printf("seed=%d\n", p.Seed)
+	fmt.Printf("seed=%d\n", pop.Seed)
 	fmt.Printf("Best fitness: %d\n", fit)
+	reportLowerBound(g, fit)
 
 	seq.WriteToFile(seqFpath)
 }
@@ -86,28 +163,166 @@ func MemoryDriver(graphFpath, seqFpath string) {
 	}
 
 	fmt.Printf("Maximum memory footprint: %d\n", m.GetMaxUtilization())
+	reportLowerBound(g, m.GetMaxUtilization())
 }
 
-// ResumeDriver resumes a genetic algorithm from a checkpoint
-func ResumeDriver(checkpointFpath, graphFile, outFile string) {
+// HeuristicDriver builds a sequence over a graph with the deterministic heuristic `name`
+// and writes it to `seqFpath` along with printing its memory footprint
+func HeuristicDriver(graphFpath, seqFpath, name string) {
+	v := validation.NewValidator(validation.Rules{
+		validation.ValidateNonEmpty("graph", graphFpath),
+		validation.ValidateNonEmpty("out", seqFpath),
+		validation.ValidateOneOf("heuristic", name, graph.HeuristicNames()),
+	})
+	v.MustValidate()
+
+	g := loadGraphByFileType(graphFpath)
+	h, _ := graph.GetHeuristic(name)
+	s := h(g)
+
+	m, err := g.SimulateSequence(s)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("Heuristic: %s\n", name)
+	fmt.Printf("Maximum memory footprint: %d\n", m.GetMaxUtilization())
+	reportLowerBound(g, m.GetMaxUtilization())
+
+	s.WriteToFile(seqFpath)
+}
+
+// ExactDriver searches for a sequence with the smallest footprint over a graph for at most `limit`,
+// or without a limit if it is 0. It prints the optimum, or the best footprint and a lower bound
+// if it runs out of time, and the gap of the sequence at `seqFpath` if one is given. The best
+// sequence is written to `outFpath` if one is given
+func ExactDriver(graphFpath, seqFpath, outFpath string, limit time.Duration) {
+	v := validation.NewValidator(validation.Rules{
+		validation.ValidateNonEmpty("graph", graphFpath),
+	})
+	v.MustValidate()
+
+	g := loadGraphByFileType(graphFpath)
+	result := exact.Solve(g, limit)
+
+	if result.Optimal {
+		fmt.Printf("Optimal footprint: %d\n", result.Footprint)
+	} else {
+		fmt.Printf("Time limit reached after searching %d states\n", result.States)
+		fmt.Printf("Best footprint: %d\n", result.Footprint)
+		fmt.Printf("Lower bound: %d\n", result.LowerBound)
+	}
+
+	if seqFpath != "" {
+		s := sequence.LoadSequenceFromFile(seqFpath)
+		m, err := g.SimulateSequence(s)
+		if err != nil {
+			panic(err)
+		}
+		footprint := m.GetMaxUtilization()
+		fmt.Printf("Sequence footprint: %d (%s)\n", footprint, describeGap(footprint, result.LowerBound))
+	}
+
+	if outFpath != "" {
+		result.Sequence.WriteToFile(outFpath)
+	}
+}
+
+// ResumeDriver resumes any optimizer from a checkpoint on `workers` goroutines,
+// or GOMAXPROCS if it is 0. `checkpointFpath` may also be a checkpoint
+// directory, in which case the checkpoint saved last is resumed. The graph
+// the checkpoint was saved from is found from its metadata if `graphFile` is
+// empty, and a checkpoint from another graph or a newer format is only
+// resumed when `force` is set. If `runlogFpath` is given the resumed
+// generations are written to a new run log. The parameters in `overrides`
+// replace those of the checkpoint, and each change is recorded in the
+// history of the run's checkpoints
+func ResumeDriver(checkpointFpath, graphFile, outFile, runlogFpath string, workers int, force bool, overrides ResumeOverrides) {
 	v := validation.NewValidator(validation.Rules{
 		validation.ValidateNonEmpty("checkpoint", checkpointFpath),
-		validation.ValidateNonEmpty("graph", graphFile),
 		validation.ValidateNonEmpty("out", outFile),
+		validation.ValidateRangeInt(0, math.MaxInt64, workers),
 	})
+	if overrides.Epsilon != nil {
+		v.Add(validation.ValidateRangeInt(0, 1_000_000, *overrides.Epsilon))
+	}
+	if overrides.MutationRate != nil {
+		v.Add(validation.ValidateRangeFloat(0.0, 1.0, *overrides.MutationRate))
+	}
+	if overrides.Population != nil {
+		v.Add(validation.ValidateRangeInt(4, 10_000, *overrides.Population))
+	}
+	if overrides.Selection != nil {
+		v.Add(validation.ValidateNonEmpty("selection", *overrides.Selection))
+		v.Add(validation.ValidateOneOf("selection", *overrides.Selection, genetics.SelectionNames()))
+	}
+	if overrides.Crossover != nil {
+		v.Add(validation.ValidateNonEmpty("xover", *overrides.Crossover))
+		v.Add(validation.ValidateOneOf("xover", *overrides.Crossover, genetics.CrossoverNames()))
+	}
+	if overrides.MaxGenerations != nil {
+		v.Add(validation.ValidateRangeInt(0, math.MaxInt64, *overrides.MaxGenerations))
+	}
 	v.MustValidate()
 
-	p := &genetics.GA{}
-	checkpoint.Load(checkpointFpath, p)
-	p.SynchronizeRNG()
+	checkpointFpath, err := checkpoint.Resolve(checkpointFpath)
+	if err != nil {
+		fmt.Printf("unable to find the checkpoint: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Resuming from %s\n", checkpointFpath)
+
+	p, seed := loadCheckpoint(checkpointFpath, workers)
+	meta := p.GetMetadata()
 
+	if graphFile == "" {
+		if graphFile, err = locateGraph(checkpointFpath, meta); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		fmt.Printf("Resuming with graph %s\n", graphFile)
+	}
 	g := loadGraphByFileType(graphFile)
-	p.Evolve(g)
+
+	if err := meta.Verify(g.Fingerprint()); err != nil {
+		if !force {
+			fmt.Printf("unable to resume: %v. Use -force to resume anyway\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("warning: %v\n", err)
+	} else if meta.GraphFingerprint == "" {
+		fmt.Println("warning: the checkpoint doesn't record its graph, so it can't be checked")
+	}
+
+	changes, err := overrides.apply(p, g)
+	if err != nil {
+		fmt.Printf("unable to resume: %v\n", err)
+		os.Exit(1)
+	}
+	for _, change := range changes {
+		fmt.Printf("Changed %s from %s to %s\n", change.Parameter, change.From, change.To)
+	}
+
+	// the checkpoints of the resumed run describe the graph it now uses
+	resumed := newMetadata(graphFile, g, nil)
+	resumed.Parameters = meta.Parameters
+	resumed.History = append(meta.History, changes...)
+	p.SetMetadata(resumed)
+
+	closeRunLog := openRunLog(p, runlogFpath)
+
+	ctx, stop := interruptContext()
+	defer stop()
+	err = p.Evolve(ctx, g)
+	reportInterrupted(ctx)
+	closeRunLog()
+	exitOnError(err)
 
 	fit, seq := p.GetBest(g)
 
-	fmt.Printf("seed=%d\n", p.Seed)
+	fmt.Printf("seed=%d\n", seed)
 	fmt.Printf("Best fitness: %d\n", fit)
+	reportLowerBound(g, fit)
 
 	seq.WriteToFile(outFile)
 }
@@ -124,6 +339,9 @@ func ConfigDriver(configFile string) {
 		return
 	}
 
+	ctx, stop := interruptContext()
+	defer stop()
+
 	GAs := make(map[string]*config.Population)
 	for _, pop := range cfg.Populations {
 		GAs[pop.Name] = pop
@@ -137,18 +355,451 @@ func ConfigDriver(configFile string) {
 		}
 		g := loadGraphByFileType(job.GraphFile)
 
-		p := genetics.NewGA(pop.Population, pop.Epsilon, pop.MutationRate, g, pop.Seed, pop.CheckpointFreq, pop.CheckpointPath)
+		islands := make([]*config.Population, len(job.IslandPopulations))
+		for i, name := range job.IslandPopulations {
+			islands[i] = GAs[name]
+		}
+		p := newOptimizer(pop, islands, g)
+		p.SetMetadata(newMetadata(job.GraphFile, g, pop))
+		p.SeedSequences(g, loadSeeds(pop, g))
+		closeRunLog := openRunLog(p, job.RunLog)
 
 		fmt.Println(job.GraphFile)
-		p.Evolve(g)
+		err := p.Evolve(ctx, g)
+		reportInterrupted(ctx)
+		closeRunLog()
+		exitOnError(err)
 
 		fit, seq := p.GetBest(g)
 
-		fmt.Printf("seed=%d\n", p.Seed)
+		fmt.Printf("seed=%d\n", pop.Seed)
 		fmt.Printf("Best fitness: %d\n", fit)
+		reportLowerBound(g, fit)
 
 		seq.WriteToFile(fmt.Sprintf("%s/%s", job.OutputDir, "final.seq"))
+
+		// the remaining jobs aren't started once we have been interrupted
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// interruptContext returns a context which is cancelled by the first
+// SIGINT or SIGTERM so that an optimizer stops after its current generation
+// and the best sequence so far is written. Any further signal terminates
+// the process as usual
+func interruptContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx, stop
+}
+
+// openRunLog adds a run log written to `path` to the observers of `p` and
+// returns a function which closes it. Nothing is logged if `path` is empty
+func openRunLog(p optimize.Optimizer, path string) func() {
+	if path == "" {
+		return func() {}
+	}
+
+	l, err := runlog.Create(path)
+	if err != nil {
+		fmt.Printf("unable to create run log: %v\n", err)
+		os.Exit(1)
+	}
+	p.AddObserver(l)
+
+	return func() {
+		if err := l.Close(); err != nil {
+			fmt.Printf("error writing run log: %v\n", err)
+		}
+	}
+}
+
+// exitOnError exits if an optimizer failed. The sequence of a failed run
+// isn't written since the error may have come from the best gene
+func exitOnError(err error) {
+	if err != nil {
+		fmt.Printf("error while evolving: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// reportInterrupted tells the user the results are from an interrupted run
+func reportInterrupted(ctx context.Context) {
+	if ctx.Err() != nil {
+		fmt.Println("Interrupted, writing the best sequence found so far")
+	}
+}
+
+// newOptimizer creates the optimizer named by `pop.Algorithm`, which is the
+// genetic algorithm described by `pop` when it is empty. When `islands`
+// lists populations, or `pop` asks for more than one island, an
+// archipelago is created instead with `pop` describing its migration,
+// master seed, epsilon and checkpoints
+func newOptimizer(pop *config.Population, islands []*config.Population, g *graph.Graph) optimize.Optimizer {
+	switch pop.Algorithm {
+	case optimize.AlgorithmAnnealing:
+		return newAnnealing(pop, g)
+	case optimize.AlgorithmTabu:
+		return newTabu(pop, g)
+	}
+
+	if len(islands) == 0 {
+		if pop.Islands <= 1 {
+			return newGA(pop, g)
+		}
+		for i := 0; i < pop.Islands; i++ {
+			islands = append(islands, pop)
+		}
+	}
+
+	seeds := genetics.IslandSeeds(pop.Seed, len(islands))
+	gas := make([]*genetics.GA, len(islands))
+	for i, island := range islands {
+		island := *island
+		island.Seed = seeds[i]
+		gas[i] = newGA(&island, g)
+	}
+
+	a := genetics.NewArchipelago(gas, pop.Topology, pop.MigrationInterval, pop.Migrants, pop.Seed, pop.Epsilon, pop.CheckpointFreq, pop.CheckpointPath)
+	a.Termination = newTermination(pop)
+	a.CheckpointPolicy = newCheckpointPolicy(pop)
+	a.Workers = pop.Workers
+	return a
+}
+
+// loadSeeds loads the sequence files and builds the heuristic sequences
+// listed by `pop` to seed an optimizer with
+func loadSeeds(pop *config.Population, g *graph.Graph) []*sequence.Sequence {
+	seeds := make([]*sequence.Sequence, 0)
+	for _, path := range pop.SeedSequences {
+		seeds = append(seeds, sequence.LoadSequenceFromFile(path))
+	}
+	for _, name := range pop.SeedHeuristics {
+		h, _ := graph.GetHeuristic(name)
+		seeds = append(seeds, h(g))
+	}
+
+	if len(seeds) > 0 {
+		log.Printf("Seeding with %d sequences\n", len(seeds))
+	}
+	return seeds
+}
+
+// loadCheckpoint loads any optimizer from a checkpoint file, ready to
+// resume evolving on `workers` goroutines, along with its seed. The state of
+// the random number generator is restored from the checkpoint, so the search
+// continues as if it had never stopped. Genetic algorithms and archipelagos
+// predate the algorithm field of their checkpoints
+func loadCheckpoint(checkpointFpath string, workers int) (optimize.Optimizer, int) {
+	var kind struct {
+		Algorithm string            `json:"algorithm"`
+		Islands   []json.RawMessage `json:"islands"`
+	}
+	checkpoint.Load(checkpointFpath, &kind)
+
+	switch kind.Algorithm {
+	case optimize.AlgorithmAnnealing:
+		a := &optimize.Annealing{}
+		checkpoint.Load(checkpointFpath, a)
+		return a, a.Seed
+	case optimize.AlgorithmTabu:
+		t := &optimize.Tabu{}
+		checkpoint.Load(checkpointFpath, t)
+		t.Workers = workers
+		return t, t.Seed
+	}
+
+	if kind.Islands != nil {
+		a := &genetics.Archipelago{}
+		checkpoint.Load(checkpointFpath, a)
+		a.Workers = workers
+		return a, a.Seed
+	}
+
+	p := &genetics.GA{}
+	checkpoint.Load(checkpointFpath, p)
+	p.Workers = workers
+	return p, p.Seed
+}
+
+// ResumeOverrides are the parameters of a run which are changed when it is
+// resumed, such as a lower mutation rate and a smaller population to
+// intensify a search which has finished exploring. Parameters which are nil
+// keep their value from the checkpoint. The population of simulated
+// annealing is the number of moves it tries at each temperature, and tabu
+// search only takes the termination rules
+type ResumeOverrides struct {
+	Epsilon        *int
+	MutationRate   *float64
+	Population     *int
+	Selection      *string
+	Crossover      *string
+	MaxGenerations *int
+}
+
+// apply changes the parameters of `p` over `g` and returns the changes it
+// made. Every island of an archipelago is changed in the same way
+func (o ResumeOverrides) apply(p optimize.Optimizer, g *graph.Graph) ([]checkpoint.Change, error) {
+	changes := make([]checkpoint.Change, 0)
+	generation := 0
+	record := func(parameter string, from, to interface{}) {
+		if fmt.Sprint(from) != fmt.Sprint(to) {
+			changes = append(changes, checkpoint.Change{Generation: generation, Parameter: parameter, From: fmt.Sprint(from), To: fmt.Sprint(to)})
+		}
+	}
+	termination := func(epsilon *int, t *genetics.Termination) {
+		if o.Epsilon != nil {
+			record("epsilon", *epsilon, *o.Epsilon)
+			*epsilon = *o.Epsilon
+		}
+		if o.MaxGenerations != nil {
+			record("maxGenerations", t.MaxGenerations, *o.MaxGenerations)
+			t.MaxGenerations = *o.MaxGenerations
+		}
+	}
+
+	switch p := p.(type) {
+	case *genetics.GA:
+		generation = p.Generations
+		termination(&p.Epsilon, &p.Termination)
+		return changes, o.applyGA(p, g, "", record)
+	case *genetics.Archipelago:
+		generation = p.Generations
+		termination(&p.Epsilon, &p.Termination)
+		if o.Population != nil && *o.Population < p.Migrants {
+			return nil, fmt.Errorf("islands of %d genes can't send %d migrants", *o.Population, p.Migrants)
+		}
+		for i, island := range p.Islands {
+			if err := o.applyGA(island, g, fmt.Sprintf("island %d ", i), record); err != nil {
+				return nil, fmt.Errorf("island %d: %w", i, err)
+			}
+		}
+		return changes, nil
+	case *optimize.Annealing:
+		generation = p.Generations
+		termination(&p.Epsilon, &p.Termination)
+		if o.Population != nil {
+			record("moves", p.Moves, *o.Population)
+			p.Moves = *o.Population
+		}
+		return changes, o.refuse(optimize.AlgorithmAnnealing, true)
+	case *optimize.Tabu:
+		generation = p.Generations
+		termination(&p.Epsilon, &p.Termination)
+		return changes, o.refuse(optimize.AlgorithmTabu, false)
+	}
+	return changes, nil
+}
+
+// applyGA changes the parameters of a genetic algorithm, naming them with
+// `prefix` in the changes it records
+func (o ResumeOverrides) applyGA(p *genetics.GA, g *graph.Graph, prefix string, record func(string, interface{}, interface{})) error {
+	if o.MutationRate != nil {
+		record(prefix+"mutationRate", p.MutationChance, *o.MutationRate)
+		p.MutationChance = *o.MutationRate
+	}
+	if o.Selection != nil {
+		record(prefix+"selection", p.Selection, *o.Selection)
+		p.Selection = *o.Selection
+	}
+	if o.Crossover != nil {
+		record(prefix+"xover", p.Crossover, *o.Crossover)
+		p.Crossover = *o.Crossover
+	}
+	if o.Population != nil && *o.Population != p.Size {
+		record(prefix+"population", p.Size, *o.Population)
+		return p.Resize(g, *o.Population)
+	}
+	return nil
+}
+
+// refuse returns an error if a parameter of the genetic algorithm was
+// given for `algorithm`, which only has a population if `population` is set
+func (o ResumeOverrides) refuse(algorithm string, population bool) error {
+	switch {
+	case o.MutationRate != nil:
+		return fmt.Errorf("-mutation doesn't apply to %s", algorithm)
+	case o.Selection != nil:
+		return fmt.Errorf("-selection doesn't apply to %s", algorithm)
+	case o.Crossover != nil:
+		return fmt.Errorf("-xover doesn't apply to %s", algorithm)
+	case o.Population != nil && !population:
+		return fmt.Errorf("-pop doesn't apply to %s", algorithm)
+	}
+	return nil
+}
+
+// newMetadata describes a run over the graph `g` loaded from `graphFpath`
+// with the parameters `pop` for its checkpoints. The path is made absolute
+// so that the graph can be found again from any directory
+func newMetadata(graphFpath string, g *graph.Graph, pop *config.Population) checkpoint.Metadata {
+	if abs, err := filepath.Abs(graphFpath); err == nil {
+		graphFpath = abs
+	}
+
+	meta := checkpoint.Metadata{
+		SagaVersion:      Version,
+		GraphPath:        graphFpath,
+		GraphFingerprint: g.Fingerprint(),
+	}
+	if pop != nil {
+		// a population always marshals
+		meta.Parameters, _ = json.Marshal(pop)
+	}
+	return meta
+}
+
+// locateGraph finds the graph a checkpoint was saved from. The path in its
+// metadata is tried first and then a file of the same name next to the
+// checkpoint, in case the run was moved
+func locateGraph(checkpointFpath string, meta checkpoint.Metadata) (string, error) {
+	if meta.GraphPath == "" {
+		return "", errors.New("the checkpoint doesn't record its graph, give it with -graph")
+	}
+
+	candidates := []string{
+		meta.GraphPath,
+		filepath.Join(filepath.Dir(checkpointFpath), filepath.Base(meta.GraphPath)),
+	}
+	for _, path := range candidates {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("unable to find the graph %s of the checkpoint, give it with -graph", meta.GraphPath)
+}
+
+// newGA creates a genetic algorithm over `g` with the parameters
+// described by `pop`. Optional parameters left at their zero value
+// keep the defaults from genetics.NewGA
+func newGA(pop *config.Population, g *graph.Graph) *genetics.GA {
+	p := genetics.NewGA(pop.Population, pop.Epsilon, pop.MutationRate, g, pop.Seed, pop.CheckpointFreq, pop.CheckpointPath)
+	p.TieBreaker = pop.TieBreaker
+	p.Elitism = pop.Elitism
+	p.MutationOperators = pop.MutationOperators
+	p.StagnationWindow = pop.StagnationWindow
+	p.ReseedFraction = pop.ReseedFraction
+	p.RestartAfter = pop.RestartAfter
+	p.LocalSearchSteps = pop.LocalSearchSteps
+	p.CacheSize = pop.FitnessCache
+	p.Workers = pop.Workers
+	p.Termination = newTermination(pop)
+	p.CheckpointPolicy = newCheckpointPolicy(pop)
+
+	if pop.Crossover != "" {
+		p.Crossover = pop.Crossover
+	}
+	if pop.CrossoverRate > 0 {
+		p.CrossoverRate = pop.CrossoverRate
+	}
+	if pop.Selection != "" {
+		p.Selection = pop.Selection
+	}
+	if pop.TournamentSize > 0 {
+		p.TournamentSize = pop.TournamentSize
+	}
+	if pop.TruncationRatio > 0 {
+		p.TruncationRatio = pop.TruncationRatio
+	}
+	if pop.MutationBoost > 0 {
+		p.MutationBoost = pop.MutationBoost
+	}
+	if pop.MaxMutation > 0 {
+		p.MaxMutation = pop.MaxMutation
+	}
+	if pop.LocalSearch != "" {
+		p.LocalSearch = pop.LocalSearch
+	}
+	if pop.LocalSearchGenes > 0 {
+		p.LocalSearchGenes = pop.LocalSearchGenes
+	}
+	if pop.Dedup != "" {
+		p.Dedup = pop.Dedup
+	}
+
+	return p
+}
+
+// newAnnealing creates a simulated annealing search over `g` which tries
+// `pop.Population` moves at each temperature, so that it evaluates as many
+// sequences per epoch as a genetic algorithm of the same size
+func newAnnealing(pop *config.Population, g *graph.Graph) *optimize.Annealing {
+	a := optimize.NewAnnealing(pop.Population, pop.Epsilon, g, pop.Seed, pop.CheckpointFreq, pop.CheckpointPath)
+	a.TieBreaker = pop.TieBreaker
+	a.Termination = newTermination(pop)
+	a.CheckpointPolicy = newCheckpointPolicy(pop)
+
+	if pop.Temperature > 0 {
+		a.Temperature = pop.Temperature
+	}
+	if pop.Cooling != "" {
+		a.Cooling = pop.Cooling
+	}
+	if pop.CoolingRate > 0 {
+		a.CoolingRate = pop.CoolingRate
+	}
+
+	return a
+}
+
+// newTabu creates a tabu search over `g` with the parameters described by `pop`
+func newTabu(pop *config.Population, g *graph.Graph) *optimize.Tabu {
+	t := optimize.NewTabu(pop.Epsilon, g, pop.Seed, pop.CheckpointFreq, pop.CheckpointPath)
+	t.TieBreaker = pop.TieBreaker
+	t.Candidates = pop.TabuCandidates
+	t.Workers = pop.Workers
+	t.Termination = newTermination(pop)
+	t.CheckpointPolicy = newCheckpointPolicy(pop)
+
+	if pop.TabuTenure > 0 {
+		t.Tenure = pop.TabuTenure
+	}
+
+	return t
+}
+
+// newTermination creates the stopping rules described by `pop`, whose time
+// limit has already been validated
+func newTermination(pop *config.Population) genetics.Termination {
+	limit, _ := pop.TimeBudget()
+	return genetics.Termination{
+		MaxGenerations: pop.MaxGenerations,
+		TimeLimit:      limit,
+		MaxEvaluations: pop.MaxEvaluations,
+		TargetFitness:  pop.TargetFitness,
+		StopAtBound:    pop.StopAtBound,
+	}
+}
+
+// newCheckpointPolicy describes how the checkpoints described by `pop` are
+// compressed and how many of them are kept
+func newCheckpointPolicy(pop *config.Population) checkpoint.Policy {
+	return checkpoint.Policy{
+		Compress:  pop.CheckpointCompress,
+		KeepLast:  pop.CheckpointKeep,
+		KeepEvery: pop.CheckpointKeepEvery,
+	}
+}
+
+// reportLowerBound prints the lower bound on the footprint of any sequence
+// of `g` and how far `footprint` is from it
+func reportLowerBound(g *graph.Graph, footprint int) {
+	bound := g.LowerBound()
+	fmt.Printf("Lower bound: %d (%s)\n", bound, describeGap(footprint, bound))
+}
+
+// describeGap formats how far `footprint` is from the lower bound `bound`,
+// as a percentage of the bound unless it is 0
+func describeGap(footprint, bound int) string {
+	if bound == 0 {
+		return fmt.Sprintf("gap %d", footprint)
 	}
+	return fmt.Sprintf("gap %d, %.1f%%", footprint-bound, 100*float64(footprint-bound)/float64(bound))
 }
 
 func loadGraphByFileType(graphFpath string) *graph.Graph {
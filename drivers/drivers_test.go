@@ -0,0 +1,39 @@
+package drivers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/andey-robins/magical/config"
+	"github.com/andey-robins/magical/genetics"
+	"github.com/andey-robins/magical/parsers/blif"
+)
+
+func TestNewGAKeepsDefaultCrossoverRate(t *testing.T) {
+	g := blif.LoadBlifAsGraph("../input/circuits/cm150a_128.blif")
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{
+  "populations": [{"name": "no rate", "population": 10, "xover": "default", "mutationRate": 0.2, "epsilon": 5, "seed": 1}],
+  "jobs": []
+}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	pop := newGA(config.ParseConfig(path).Populations[0], g)
+	if pop.CrossoverRate != 1 {
+		t.Fatalf("expected a population without xoverRate to always cross over, got a rate of %v", pop.CrossoverRate)
+	}
+
+	plain := genetics.NewGA(10, 5, 0.2, g, 1, 0, "")
+	pop.MaxGenerations, plain.MaxGenerations = 3, 3
+	pop.Evolve(context.Background(), g)
+	plain.Evolve(context.Background(), g)
+	for i := range plain.Genes {
+		if pop.Genes[i].Sequence.ToString() != plain.Genes[i].Sequence.ToString() {
+			t.Fatalf("expected gene %d to be bred like genetics.NewGA breeds it", i)
+		}
+	}
+}
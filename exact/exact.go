@@ -0,0 +1,267 @@
+package exact
+
+// The exact solver finds a sequence with the smallest possible memory
+// footprint. It is exponential in the number of gates, so it is meant for
+// circuits of a few dozen gates where it tells us how far the heuristics
+// and optimizers are from the optimum.
+
+import (
+	"time"
+
+	"github.com/andey-robins/magical/graph"
+	"github.com/andey-robins/magical/memory"
+	"github.com/andey-robins/magical/sequence"
+)
+
+// A Result is the outcome of solving a graph. Sequence is the best
+// sequence found and Footprint is its footprint. No sequence has a
+// footprint below LowerBound, so when Optimal is true they are equal
+type Result struct {
+	Sequence   *sequence.Sequence
+	Footprint  int
+	LowerBound int
+	Optimal    bool
+	States     int // the number of sets of gates searched
+}
+
+// Solve searches for a sequence of `g` with the smallest footprint under the
+// same memory semantics as graph.SimulateSequence. It tries each bound on
+// the footprint in turn, from graph.LowerBound up to the footprint of the
+// greedy heuristic, and searches depth first for a sequence which stays
+// within the bound. Sets of processed gates which can't be completed within
+// the bound are remembered so they are only searched once.
+//
+// If `limit` is positive and the search takes longer, the best sequence
+// found so far is returned along with the bound being searched, which is
+// still a lower bound since every smaller bound was ruled out
+func Solve(g *graph.Graph, limit time.Duration) *Result {
+	s := newSolver(g)
+	if limit > 0 {
+		s.deadline = time.Now().Add(limit)
+	}
+
+	best := g.GreedySequence()
+	mem, err := g.SimulateSequence(best)
+	if err != nil {
+		panic(err)
+	}
+	result := &Result{Sequence: best, Footprint: mem.GetMaxUtilization()}
+
+	for bound := g.LowerBound(); bound < result.Footprint; bound++ {
+		result.LowerBound = bound
+		if s.feasible(bound) {
+			result.Sequence = sequence.NewSequence(s.order)
+			result.Footprint = bound
+			break
+		}
+		if s.timedOut {
+			result.States = s.states
+			return result
+		}
+	}
+
+	result.LowerBound = result.Footprint
+	result.Optimal = true
+	result.States = s.states
+	return result
+}
+
+// solver holds the state of the depth first search. Gates are numbered
+// in the order they are listed in the graph and nodes are referred to by
+// their ids everywhere else
+type solver struct {
+	gates    []*graph.Node
+	isGate   map[int]int // the number of each gate by id
+	children [][]int     // the numbers of each gate's children
+	parents  [][]int     // the ids of each gate's parents
+
+	// the state of the partial sequence: the number of unprocessed
+	// children of each node, the number of unprocessed parents of each
+	// gate, the processed gates, and the number of live cells
+	references map[int]int
+	waiting    []int
+	done       []byte
+	order      []int
+	live       int
+
+	initial  int // the footprint of loading the inputs
+	bound    int
+	failed   map[string]bool
+	states   int
+	deadline time.Time
+	timedOut bool
+}
+
+func newSolver(g *graph.Graph) *solver {
+	s := &solver{
+		isGate:     make(map[int]int),
+		references: make(map[int]int),
+	}
+
+	// load the inputs the same way as graph.SimulateSequence does
+	mem := memory.NewMemory()
+	seen := make(map[int]bool)
+	for _, node := range g.GetNodes() {
+		if seen[node.GetId()] {
+			continue
+		}
+		seen[node.GetId()] = true
+		s.references[node.GetId()] = node.GetChildCount()
+
+		if !node.HasAnyParents() {
+			mem.ProcessNode(node.GetId(), node.GetChildCount(), []int{})
+			if node.HasAnyChildren() {
+				s.live++
+			}
+			continue
+		}
+		s.isGate[node.GetId()] = len(s.gates)
+		s.gates = append(s.gates, node)
+	}
+	s.initial = mem.GetMaxUtilization()
+
+	s.children = make([][]int, len(s.gates))
+	s.parents = make([][]int, len(s.gates))
+	s.waiting = make([]int, len(s.gates))
+	for i, gate := range s.gates {
+		s.parents[i] = gate.GetParentIds()
+		for _, id := range s.parents[i] {
+			if _, ok := s.isGate[id]; ok {
+				s.waiting[i]++
+			}
+		}
+		for _, id := range gate.GetChildIds() {
+			s.children[i] = append(s.children[i], s.isGate[id])
+		}
+	}
+	s.done = make([]byte, (len(s.gates)+7)/8)
+
+	return s
+}
+
+// feasible returns true and leaves the sequence in s.order if there is a
+// sequence with a footprint of at most `bound`
+func (s *solver) feasible(bound int) bool {
+	if s.initial > bound {
+		return false
+	}
+	s.bound = bound
+	s.failed = make(map[string]bool)
+	return s.search()
+}
+
+// search extends the partial sequence to a complete one within the bound
+func (s *solver) search() bool {
+	if len(s.order) == len(s.gates) {
+		return true
+	}
+
+	// every gate is written to a new cell while the live cells are held
+	if s.live+1 > s.bound || s.timedOut {
+		return false
+	}
+
+	key := string(s.done)
+	if s.failed[key] {
+		return false
+	}
+
+	s.states++
+	if !s.deadline.IsZero() && s.states%1024 == 0 && time.Now().After(s.deadline) {
+		s.timedOut = true
+		return false
+	}
+
+	ready := make([]int, 0)
+	for i := range s.gates {
+		if s.waiting[i] == 0 && !s.isDone(i) {
+			ready = append(ready, i)
+		}
+	}
+
+	// a gate which doesn't add to the live cells can always be processed
+	// right away: moving it earlier in any sequence never raises the
+	// footprint, so it is the only choice we need to try
+	for _, i := range ready {
+		if s.gain(i) <= 0 {
+			ready = []int{i}
+			break
+		}
+	}
+
+	for _, i := range ready {
+		freed := s.process(i)
+		if s.search() {
+			return true
+		}
+		s.undo(i, freed)
+	}
+
+	if !s.timedOut {
+		s.failed[key] = true
+	}
+	return false
+}
+
+// gain returns the change in live cells from processing gate `i`
+func (s *solver) gain(i int) int {
+	uses := make(map[int]int)
+	for _, id := range s.parents[i] {
+		uses[id]++
+	}
+
+	gain := 0
+	if len(s.children[i]) > 0 {
+		gain++
+	}
+	for id, n := range uses {
+		if s.references[id] == n {
+			gain--
+		}
+	}
+	return gain
+}
+
+// process appends gate `i` to the sequence and returns the number of
+// cells it freed
+func (s *solver) process(i int) int {
+	freed := 0
+	for _, id := range s.parents[i] {
+		s.references[id]--
+		if s.references[id] == 0 {
+			freed++
+		}
+	}
+	for _, child := range s.children[i] {
+		s.waiting[child]--
+	}
+	if len(s.children[i]) > 0 {
+		s.live++
+	}
+	s.live -= freed
+
+	s.done[i/8] |= 1 << (i % 8)
+	s.order = append(s.order, s.gates[i].GetId())
+	return freed
+}
+
+// undo reverses process(i)
+func (s *solver) undo(i, freed int) {
+	s.order = s.order[:len(s.order)-1]
+	s.done[i/8] &^= 1 << (i % 8)
+
+	s.live += freed
+	if len(s.children[i]) > 0 {
+		s.live--
+	}
+	for _, child := range s.children[i] {
+		s.waiting[child]++
+	}
+	for _, id := range s.parents[i] {
+		s.references[id]++
+	}
+}
+
+func (s *solver) isDone(i int) bool {
+	return s.done[i/8]&(1<<(i%8)) != 0
+}
@@ -0,0 +1,81 @@
+package exact
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andey-robins/magical/graph"
+	"github.com/andey-robins/magical/parsers/blif"
+	"github.com/andey-robins/magical/sequence"
+)
+
+// bruteForce returns the smallest footprint of every valid sequence of `g`
+func bruteForce(g *graph.Graph) int {
+	gates := make([]*graph.Node, 0)
+	for _, node := range g.GetNodes() {
+		if node.HasAnyParents() {
+			gates = append(gates, node)
+		}
+	}
+
+	best := -1
+	var permute func(order []int, used map[int]bool)
+	permute = func(order []int, used map[int]bool) {
+		if len(order) == len(gates) {
+			s := sequence.NewSequence(append([]int{}, order...))
+			if mem, err := g.SimulateSequence(s); err == nil && (best < 0 || mem.GetMaxUtilization() < best) {
+				best = mem.GetMaxUtilization()
+			}
+			return
+		}
+		for _, gate := range gates {
+			if !used[gate.GetId()] {
+				used[gate.GetId()] = true
+				permute(append(order, gate.GetId()), used)
+				used[gate.GetId()] = false
+			}
+		}
+	}
+	permute([]int{}, make(map[int]bool))
+	return best
+}
+
+func TestSolveIsOptimal(t *testing.T) {
+	graphs := []string{
+		"Inputs 3\n1 2 3\nOutputs 1\n4\nNodes 4\nEdges 6\n1 5\n2 7\n3 6\n5 7\n6 4\n7 4",
+		"Inputs 3\n1 2 3\nOutputs 1\n4\nNodes 5\nEdges 10\n1 5\n2 5\n1 7\n3 7\n2 8\n3 8\n7 6\n8 6\n5 4\n6 4",
+		"Inputs 2\n1 2\nOutputs 2\n3 4\nNodes 7\nEdges 13\n1 5\n2 5\n1 6\n2 6\n5 7\n6 7\n5 8\n6 8\n7 9\n8 9\n9 3\n5 4\n8 4",
+	}
+
+	for _, graphString := range graphs {
+		g := graph.LoadGraphFromString(graphString)
+		result := Solve(g, 0)
+
+		if !result.Optimal || result.LowerBound != result.Footprint {
+			t.Errorf("expected an optimal result, got %+v", result)
+		}
+		if expected := bruteForce(g); result.Footprint != expected {
+			t.Errorf("expected optimum %d, got %d", expected, result.Footprint)
+		}
+
+		mem, err := g.SimulateSequence(result.Sequence)
+		if err != nil || mem.GetMaxUtilization() != result.Footprint {
+			t.Errorf("expected the sequence to have footprint %d", result.Footprint)
+		}
+	}
+}
+
+func TestSolveTimeLimit(t *testing.T) {
+	g := blif.LoadBlifAsGraph("../input/circuits/5xp1_90.blif")
+	result := Solve(g, 50*time.Millisecond)
+
+	if result.Optimal {
+		t.Skip("solved within the time limit")
+	}
+	if result.LowerBound > result.Footprint {
+		t.Errorf("expected lower bound %d to be at most footprint %d", result.LowerBound, result.Footprint)
+	}
+	if !g.IsValidSequence(result.Sequence) {
+		t.Errorf("expected a valid sequence after timing out")
+	}
+}
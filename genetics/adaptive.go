@@ -0,0 +1,103 @@
+package genetics
+
+import (
+	"fmt"
+	"log"
+	"math"
+
+	"github.com/andey-robins/magical/graph"
+)
+
+// maxMutationLevel caps how many times the mutation strength is raised
+// so that a long stagnation can't make every mutation arbitrarily slow
+const maxMutationLevel = 10
+
+// adaptive returns true if any adaptive control is enabled
+func (p *GA) adaptive() bool {
+	return p.StagnationWindow > 0 || p.RestartAfter > 0
+}
+
+// mutationChance returns the chance of mutating a gene after the adaptive
+// mutation level has been applied to MutationChance
+func (p *GA) mutationChance() float64 {
+	if p.MutationLevel == 0 {
+		return p.MutationChance
+	}
+
+	maxChance := p.MaxMutation
+	if maxChance <= 0 {
+		maxChance = 1
+	}
+	return math.Min(maxChance, p.MutationChance*math.Pow(p.MutationBoost, float64(p.MutationLevel)))
+}
+
+// mutationSteps returns the number of times a mutation operator is applied
+// to each mutated gene. Every level of adaptive mutation adds one more
+func (p *GA) mutationSteps() int {
+	return 1 + p.MutationLevel
+}
+
+// adapt updates the adaptive control after each generation. Every
+// StagnationWindow generations without improvement the mutation level is
+// raised and ReseedFraction of the population is replaced with fresh random
+// genes. After RestartAfter generations without improvement everything but
+// the elite is replaced. Any improvement resets the mutation level
+//
+// This function uses random numbers, but pulls from p.RNG which is seeded
+// deterministically and doesn't spawn any go-routines
+func (p *GA) adapt(g *graph.Graph, improved bool) {
+	if !p.adaptive() {
+		return
+	}
+
+	if improved {
+		p.Stagnation = 0
+		p.MutationLevel = 0
+		return
+	}
+	p.Stagnation++
+
+	if p.RestartAfter > 0 && p.Stagnation >= p.RestartAfter {
+		log.Printf("Epoch %d: Restarting after %d generations without improvement\n", p.Generations, p.Stagnation)
+		p.restart(g)
+		return
+	}
+
+	if p.StagnationWindow > 0 && p.Stagnation%p.StagnationWindow == 0 {
+		if p.MutationLevel < maxMutationLevel {
+			p.MutationLevel++
+		}
+		p.reseed(g, int(p.ReseedFraction*float64(p.Size)))
+	}
+}
+
+// restart replaces everything except the elite, or the best gene when
+// there is no elitism, with fresh random genes
+func (p *GA) restart(g *graph.Graph) {
+	if p.Elitism < 1 {
+		best := *p.BestGene
+		p.Genes[0] = &best
+	}
+
+	p.reseed(g, len(p.Genes)-max(p.Elitism, 1))
+	p.Stagnation = 0
+	p.MutationLevel = 0
+}
+
+// reseed replaces the last `n` genes of the population with fresh random
+// genes. The elite at the front of the population are never replaced. The
+// new genes are evaluated with the rest of the population next generation
+func (p *GA) reseed(g *graph.Graph, n int) {
+	n = min(n, len(p.Genes)-min(p.Elitism, len(p.Genes)))
+	for i := len(p.Genes) - n; i < len(p.Genes); i++ {
+		p.Genes[i] = &Gene{Sequence: g.SynthesizeRandomValidSequence(p.RNG.Int())}
+	}
+}
+
+// describeAdaptive formats the adaptive control for the epoch report
+func (p *GA) describeAdaptive() string {
+	if !p.adaptive() {
+		return ""
+	}
+	return fmt.Sprintf(" Mutation: %.3f x%d Stagnation: %d", p.mutationChance(), p.mutationSteps(), p.Stagnation)
+}
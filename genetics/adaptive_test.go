@@ -0,0 +1,57 @@
+package genetics
+
+import (
+	"math"
+	"testing"
+
+	"github.com/andey-robins/magical/graph"
+)
+
+func TestAdaptiveControl(t *testing.T) {
+	g := graph.LoadGraphFromString("Inputs 3\n1 2 3\nOutputs 1\n4\nNodes 4\nEdges 6\n1 5\n2 7\n3 6\n5 7\n6 4\n7 4")
+
+	pop := NewGA(20, 1, 0.2, g, 1, 0, "")
+	pop.StagnationWindow = 2
+	pop.ReseedFraction = 0.5
+	pop.RestartAfter = 6
+	pop.SynchronizeRNG()
+
+	original := append(make([]*Gene, 0), pop.Genes...)
+
+	pop.adapt(g, false)
+	pop.adapt(g, false)
+
+	if pop.MutationLevel != 1 {
+		t.Errorf("Expected mutation level 1 after a stagnation window, got %d", pop.MutationLevel)
+	}
+	if math.Abs(pop.mutationChance()-0.3) > 1e-9 || pop.mutationSteps() != 2 {
+		t.Errorf("Expected boosted mutation, got chance %v and %d steps", pop.mutationChance(), pop.mutationSteps())
+	}
+	for i := range pop.Genes {
+		if reseeded := pop.Genes[i] != original[i]; reseeded != (i >= 10) {
+			t.Errorf("Expected only the last half of the population to be reseeded, gene %d reseeded=%t", i, reseeded)
+		}
+	}
+
+	pop.adapt(g, true)
+	if pop.MutationLevel != 0 || pop.Stagnation != 0 {
+		t.Errorf("Expected an improvement to reset adaptive control")
+	}
+
+	original = append(make([]*Gene, 0), pop.Genes...)
+	for i := 0; i < pop.RestartAfter; i++ {
+		pop.adapt(g, false)
+	}
+
+	if pop.Stagnation != 0 {
+		t.Errorf("Expected a restart to reset stagnation, got %d", pop.Stagnation)
+	}
+	if pop.Genes[0].Sequence != pop.BestGene.Sequence {
+		t.Errorf("Expected the best gene to survive a restart")
+	}
+	for i := 1; i < len(pop.Genes); i++ {
+		if pop.Genes[i] == original[i] {
+			t.Errorf("Expected gene %d to be replaced by the restart", i)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package genetics
+
+import (
+	"container/list"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"sync"
+
+	"github.com/andey-robins/magical/sequence"
+)
+
+// A FitnessCache remembers the fitness of recently evaluated sequences so
+// that genes which survive a generation unchanged, or which crossover
+// recreates, aren't simulated again. It holds at most its capacity of
+// sequences and forgets the least recently used first. Sequences are
+// keyed by a 64-bit hash of their order, see HashSequence, and the order is
+// stored alongside the fitness so that two sequences whose hashes collide
+// are never given each other's fitness.
+//
+// The fitness depends on the tie-breaker, so a cache must only be shared
+// by populations with the same tie-breaker. It is safe for concurrent use.
+type FitnessCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[uint64]*list.Element
+	order    *list.List // the most recently used entry is at the front
+
+	hits, misses int
+}
+
+type cacheEntry struct {
+	key       uint64
+	order     []int
+	fitness   int
+	secondary int
+}
+
+// NewFitnessCache creates an empty cache of at most `capacity` sequences
+func NewFitnessCache(capacity int) *FitnessCache {
+	return &FitnessCache{
+		capacity: capacity,
+		entries:  make(map[uint64]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// HashSequence returns the FNV-1a hash of the order of `seq`
+func HashSequence(seq *sequence.Sequence) uint64 {
+	return hashOrder(seq.GetSequence())
+}
+
+// hashOrder returns the FNV-1a hash of the node ids in `order`
+func hashOrder(order []int) uint64 {
+	h := fnv.New64a()
+	buf := make([]byte, 8)
+	for _, id := range order {
+		binary.LittleEndian.PutUint64(buf, uint64(id))
+		h.Write(buf)
+	}
+	return h.Sum64()
+}
+
+// Get returns the fitness and secondary fitness of `seq` if it is cached
+func (c *FitnessCache) Get(seq *sequence.Sequence) (int, int, bool) {
+	order := seq.GetSequence()
+	key := hashOrder(order)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok || !slices.Equal(element.Value.(*cacheEntry).order, order) {
+		c.misses++
+		return 0, 0, false
+	}
+	c.hits++
+	c.order.MoveToFront(element)
+	entry := element.Value.(*cacheEntry)
+	return entry.fitness, entry.secondary, true
+}
+
+// Put caches the fitness and secondary fitness of `seq`, forgetting the
+// least recently used sequence if the cache is full. A sequence whose hash
+// collides with a cached one replaces it
+func (c *FitnessCache) Put(seq *sequence.Sequence, fitness, secondary int) {
+	order := seq.GetSequence()
+	key := hashOrder(order)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.order.MoveToFront(element)
+		entry := element.Value.(*cacheEntry)
+		entry.order, entry.fitness, entry.secondary = order, fitness, secondary
+		return
+	}
+
+	if c.capacity <= 0 {
+		return
+	}
+	if c.order.Len() >= c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key, order, fitness, secondary})
+}
+
+// Len returns the number of cached sequences
+func (c *FitnessCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// Stats returns the number of lookups which found their sequence and the
+// number which didn't
+func (c *FitnessCache) Stats() (hits, misses int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
+
+// cachedFitness fills in the fitness of every gene whose sequence is in the
+// population's fitness cache, creating the cache if needed, and returns the
+// genes which still need to be evaluated
+func (p *GA) cachedFitness() []*Gene {
+	if p.CacheSize <= 0 {
+		return p.Genes
+	}
+	if p.cache == nil {
+		p.cache = NewFitnessCache(p.CacheSize)
+	}
+
+	pending := make([]*Gene, 0, len(p.Genes))
+	for _, gene := range p.Genes {
+		fitness, secondary, ok := p.cache.Get(gene.Sequence)
+		if !ok {
+			pending = append(pending, gene)
+			continue
+		}
+		gene.Fitness, gene.Secondary = fitness, secondary
+	}
+	return pending
+}
+
+// cacheStats returns the hits and misses of the population's fitness cache
+func (p *GA) cacheStats() (int, int) {
+	if p.cache == nil {
+		return 0, 0
+	}
+	return p.cache.Stats()
+}
+
+// describeCache formats the hit rate of the fitness cache for the epoch report
+func (p *GA) describeCache() string {
+	hits, misses := p.cacheStats()
+	if hits+misses == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" Cache hits: %.1f%%", 100*float64(hits)/float64(hits+misses))
+}
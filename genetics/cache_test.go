@@ -0,0 +1,88 @@
+package genetics
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/andey-robins/magical/parsers/blif"
+	"github.com/andey-robins/magical/sequence"
+)
+
+func TestFitnessCacheEviction(t *testing.T) {
+	a := sequence.NewSequence([]int{1, 2, 3})
+	b := sequence.NewSequence([]int{1, 3, 2})
+	c := sequence.NewSequence([]int{3, 2, 1})
+
+	cache := NewFitnessCache(2)
+	cache.Put(a, 1, 10)
+	cache.Put(b, 2, 20)
+
+	// looking up a makes b the least recently used
+	if fitness, secondary, ok := cache.Get(a); !ok || fitness != 1 || secondary != 10 {
+		t.Errorf("expected a to be cached with fitness 1 and 10, got %d, %d, %t", fitness, secondary, ok)
+	}
+	cache.Put(c, 3, 30)
+
+	if _, _, ok := cache.Get(b); ok {
+		t.Error("expected b to be evicted")
+	}
+	if _, _, ok := cache.Get(c); !ok {
+		t.Error("expected c to be cached")
+	}
+	if cache.Len() != 2 {
+		t.Errorf("expected 2 cached sequences, got %d", cache.Len())
+	}
+	if hits, misses := cache.Stats(); hits != 2 || misses != 1 {
+		t.Errorf("expected 2 hits and 1 miss, got %d and %d", hits, misses)
+	}
+}
+
+func TestHashSequence(t *testing.T) {
+	a := HashSequence(sequence.NewSequence([]int{1, 2, 3}))
+	if a != HashSequence(sequence.NewSequence([]int{1, 2, 3})) {
+		t.Error("expected equal sequences to hash equally")
+	}
+	if a == HashSequence(sequence.NewSequence([]int{1, 3, 2})) {
+		t.Error("expected different orders to hash differently")
+	}
+}
+
+func TestFitnessCacheCollision(t *testing.T) {
+	a := sequence.NewSequence([]int{1, 2, 3})
+	b := sequence.NewSequence([]int{1, 3, 2})
+
+	cache := NewFitnessCache(2)
+	cache.Put(a, 1, 10)
+	// pretend b hashes like a by filing a's fitness under b's order
+	cache.entries[HashSequence(a)].Value.(*cacheEntry).order = b.GetSequence()
+
+	if _, _, ok := cache.Get(a); ok {
+		t.Error("expected a sequence colliding with another not to be given its fitness")
+	}
+	cache.Put(a, 1, 10)
+	if fitness, _, ok := cache.Get(a); !ok || fitness != 1 {
+		t.Errorf("expected a to replace the colliding sequence, got %d, %t", fitness, ok)
+	}
+}
+
+func TestCachedEvolutionIsUnchanged(t *testing.T) {
+	g := blif.LoadBlifAsGraph("../input/circuits/cm150a_128.blif")
+
+	plain := NewGA(20, 5, 0.2, g, 1, 0, "")
+	cached := NewGA(20, 5, 0.2, g, 1, 0, "")
+	cached.CacheSize = 100
+
+	plain.Evolve(context.Background(), g)
+	cached.Evolve(context.Background(), g)
+
+	if !reflect.DeepEqual(plain.BestGene, cached.BestGene) || plain.Generations != cached.Generations {
+		t.Error("expected the fitness cache not to change the evolution")
+	}
+
+	hits, misses := cached.cacheStats()
+	if hits == 0 || cached.Evaluations != misses || cached.Evaluations+hits != plain.Evaluations {
+		t.Errorf("expected %d lookups split between hits and evaluations, got %d hits and %d evaluations",
+			plain.Evaluations, hits, cached.Evaluations)
+	}
+}
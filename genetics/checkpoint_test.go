@@ -1,12 +1,15 @@
 package genetics
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"reflect"
 	"testing"
 
 	"github.com/andey-robins/magical/checkpoint"
 	"github.com/andey-robins/magical/graph"
+	"github.com/andey-robins/magical/parsers/blif"
 )
 
 func TestCheckpointSaveAndLoad(t *testing.T) {
@@ -60,7 +63,7 @@ func TestExperimentResume(t *testing.T) {
 
 	pop := NewGA(10, 1, 0.1, g, 0, 0, tempDir)
 
-	pop.Evolve(g)
+	pop.Evolve(context.Background(), g)
 
 	checkpoint.Save(cname, pop)
 
@@ -70,8 +73,8 @@ func TestExperimentResume(t *testing.T) {
 	pop.SynchronizeRNG()
 	loadedPop.SynchronizeRNG()
 
-	pop.Evolve(g)
-	loadedPop.Evolve(g)
+	pop.Evolve(context.Background(), g)
+	loadedPop.Evolve(context.Background(), g)
 
 	if loadedPop.Size != pop.Size {
 		t.Errorf("Expected size %d, got %d", pop.Size, loadedPop.Size)
@@ -111,3 +114,116 @@ func TestExperimentResume(t *testing.T) {
 		}
 	}
 }
+
+func TestCheckpointMetadata(t *testing.T) {
+	cname := os.TempDir() + "/test.json"
+
+	g := graph.LoadGraphFromString("Inputs 3\n1 2 3\nOutputs 1\n4\nNodes 4\nEdges 6\n1 5\n2 7\n3 6\n5 7\n6 4")
+
+	pop := NewGA(2, 1, 0.1, g, 0, 0, "")
+	pop.SetMetadata(checkpoint.Metadata{GraphPath: "g.graph", GraphFingerprint: g.Fingerprint()})
+	checkpoint.Save(cname, pop)
+
+	loadedPop := &GA{}
+	checkpoint.Load(cname, loadedPop)
+
+	meta := loadedPop.GetMetadata()
+	if meta.FormatVersion != checkpoint.FormatVersion || meta.GraphPath != "g.graph" {
+		t.Errorf("Expected format %d and graph g.graph, got %d and %s", checkpoint.FormatVersion, meta.FormatVersion, meta.GraphPath)
+	}
+	if err := meta.Verify(g.Fingerprint()); err != nil {
+		t.Errorf("Expected the checkpoint to match its graph, got %v", err)
+	}
+}
+
+func TestResumeMatchesUninterruptedRun(t *testing.T) {
+	g := blif.LoadBlifAsGraph("../input/circuits/cm150a_128.blif")
+	cname := os.TempDir() + "/resume.json"
+
+	newGA := func() *GA {
+		pop := NewGA(20, 6, 0.5, g, 4, 0, "")
+		pop.Selection = SelectionTournament
+		pop.MutationOperators = map[string]float64{"swap": 1, "insert": 1, "reverse": 1}
+		pop.StagnationWindow = 2
+		pop.Dedup = DedupMutate
+		pop.MaxGenerations = 40
+		return pop
+	}
+
+	uninterrupted := newGA()
+	if err := uninterrupted.Evolve(context.Background(), g); err != nil {
+		t.Fatal(err)
+	}
+	if uninterrupted.Generations <= 5 {
+		t.Fatalf("expected the run to last more than 5 generations, got %d", uninterrupted.Generations)
+	}
+
+	interrupted := newGA()
+	interrupted.MaxGenerations = 5
+	if err := interrupted.Evolve(context.Background(), g); err != nil {
+		t.Fatal(err)
+	}
+	checkpoint.Save(cname, interrupted)
+
+	resumed := &GA{}
+	checkpoint.Load(cname, resumed)
+	resumed.MaxGenerations = 40
+	if err := resumed.Evolve(context.Background(), g); err != nil {
+		t.Fatal(err)
+	}
+
+	if resumed.Generations != uninterrupted.Generations || resumed.StopReason != uninterrupted.StopReason {
+		t.Errorf("expected to stop after %d generations by %s, got %d by %s",
+			uninterrupted.Generations, uninterrupted.StopReason, resumed.Generations, resumed.StopReason)
+	}
+	if !reflect.DeepEqual(resumed.Genes, uninterrupted.Genes) || !reflect.DeepEqual(resumed.BestGene, uninterrupted.BestGene) {
+		t.Errorf("expected the resumed population to match the uninterrupted one")
+	}
+	if resumed.MutationChance != uninterrupted.MutationChance {
+		t.Errorf("expected mutation chance %f, got %f", uninterrupted.MutationChance, resumed.MutationChance)
+	}
+}
+
+func TestResumeArchipelagoMatchesUninterruptedRun(t *testing.T) {
+	g := blif.LoadBlifAsGraph("../input/circuits/cm150a_128.blif")
+	cname := os.TempDir() + "/resume-islands.json"
+
+	newArchipelago := func(maxGenerations int) *Archipelago {
+		seeds := IslandSeeds(3, 5)
+		islands := make([]*GA, len(seeds))
+		for i, seed := range seeds {
+			islands[i] = NewGA(10, 6, 0.3, g, seed, 0, "")
+		}
+		a := NewArchipelago(islands, TopologyRing, 3, 1, 5, 6, 0, "")
+		a.MaxGenerations = maxGenerations
+		return a
+	}
+
+	uninterrupted := newArchipelago(20)
+	if err := uninterrupted.Evolve(context.Background(), g); err != nil {
+		t.Fatal(err)
+	}
+
+	interrupted := newArchipelago(4)
+	if err := interrupted.Evolve(context.Background(), g); err != nil {
+		t.Fatal(err)
+	}
+	checkpoint.Save(cname, interrupted)
+
+	resumed := &Archipelago{}
+	checkpoint.Load(cname, resumed)
+	resumed.MaxGenerations = 20
+	if err := resumed.Evolve(context.Background(), g); err != nil {
+		t.Fatal(err)
+	}
+
+	if resumed.Generations != uninterrupted.Generations || resumed.StopReason != uninterrupted.StopReason {
+		t.Errorf("expected to stop after %d generations by %s, got %d by %s",
+			uninterrupted.Generations, uninterrupted.StopReason, resumed.Generations, resumed.StopReason)
+	}
+	for i := range uninterrupted.Islands {
+		if !reflect.DeepEqual(resumed.Islands[i].Genes, uninterrupted.Islands[i].Genes) {
+			t.Errorf("expected island %d to match the uninterrupted run", i)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package genetics
+
+import (
+	"math/rand/v2"
+	"sort"
+
+	"github.com/andey-robins/magical/graph"
+	"github.com/andey-robins/magical/sequence"
+)
+
+// A CrossoverOperator combines two parent sequences into two children.
+// Every operator must return sequences which are valid for the graph
+// when both parents are valid, either because the operator preserves
+// precedence by construction or because it repairs its result.
+type CrossoverOperator interface {
+	Cross(g *graph.Graph, a, b []int, rng *rand.Rand) ([]int, []int)
+}
+
+// CrossoverFunc adapts a plain function to the CrossoverOperator interface
+type CrossoverFunc func(g *graph.Graph, a, b []int, rng *rand.Rand) ([]int, []int)
+
+func (f CrossoverFunc) Cross(g *graph.Graph, a, b []int, rng *rand.Rand) ([]int, []int) {
+	return f(g, a, b, rng)
+}
+
+// crossoverOperators maps the names used in configs and on the command
+// line to their operators
+var crossoverOperators = map[string]CrossoverOperator{
+	"default": CrossoverFunc(onePointCrossover),
+	"order":   CrossoverFunc(orderCrossover),
+	"ppx":     CrossoverFunc(precedencePreservingCrossover),
+	"uniform": CrossoverFunc(uniformOrderCrossover),
+	"pmx":     CrossoverFunc(partiallyMappedCrossover),
+}
+
+// CrossoverNames returns the names of every available crossover operator
+func CrossoverNames() []string {
+	names := make([]string, 0, len(crossoverOperators))
+	for name := range crossoverOperators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetCrossover returns the crossover operator with the given name. The
+// empty name is the default operator so that populations loaded from
+// older checkpoints keep their behavior
+func GetCrossover(name string) (CrossoverOperator, bool) {
+	if name == "" {
+		name = "default"
+	}
+	op, ok := crossoverOperators[name]
+	return op, ok
+}
+
+// fillFrom appends every value of `donor` which isn't already in `child`
+// to the end of `child` in the order they appear in `donor`
+func fillFrom(child, donor []int) []int {
+	used := make(map[int]bool)
+	for _, v := range child {
+		used[v] = true
+	}
+	for _, v := range donor {
+		if !used[v] {
+			child = append(child, v)
+		}
+	}
+	return child
+}
+
+// repair makes a permutation valid for g, see graph.RepairSequence
+func repair(g *graph.Graph, genes []int) []int {
+	return g.RepairSequence(sequence.NewSequence(genes)).GetSequence()
+}
+
+// cutPoints returns two sorted cut points in [0, n]
+func cutPoints(n int, rng *rand.Rand) (int, int) {
+	i, j := rng.IntN(n+1), rng.IntN(n+1)
+	if i > j {
+		i, j = j, i
+	}
+	return i, j
+}
+
+// onePointCrossover copies the prefix of one parent up to a random point
+// and fills the rest in the order of the other parent. A prefix of a valid
+// sequence is closed under predecessors, so the result is always valid
+func onePointCrossover(g *graph.Graph, a, b []int, rng *rand.Rand) ([]int, []int) {
+	pt := rng.IntN(len(a))
+	childOne := fillFrom(append(make([]int, 0, len(a)), a[:pt]...), b)
+	childTwo := fillFrom(append(make([]int, 0, len(b)), b[:pt]...), a)
+	return childOne, childTwo
+}
+
+// orderCrossover (OX) keeps the segment between two cut points of one
+// parent in place and fills the remaining positions, starting after the
+// segment and wrapping around, in the order the other parent lists them
+// from the second cut point. The result is repaired
+func orderCrossover(g *graph.Graph, a, b []int, rng *rand.Rand) ([]int, []int) {
+	i, j := cutPoints(len(a), rng)
+
+	ox := func(keep, donor []int) []int {
+		n := len(keep)
+		child := make([]int, n)
+		used := make(map[int]bool)
+		for k := i; k < j; k++ {
+			child[k] = keep[k]
+			used[keep[k]] = true
+		}
+
+		pos := j % n
+		for k := 0; k < n; k++ {
+			v := donor[(j+k)%n]
+			if used[v] {
+				continue
+			}
+			child[pos] = v
+			pos = (pos + 1) % n
+		}
+		return repair(g, child)
+	}
+
+	return ox(a, b), ox(b, a)
+}
+
+// precedencePreservingCrossover (PPX) draws a random mask which chooses,
+// for each position, the parent to take the next unused node from. Each
+// parent is valid, so every node is taken after its predecessors and the
+// result needs no repair. The second child uses the complementary mask
+func precedencePreservingCrossover(g *graph.Graph, a, b []int, rng *rand.Rand) ([]int, []int) {
+	mask := make([]bool, len(a))
+	for k := range mask {
+		mask[k] = rng.IntN(2) == 1
+	}
+
+	ppx := func(first, second []int) []int {
+		child := make([]int, 0, len(first))
+		used := make(map[int]bool)
+		firstIdx, secondIdx := 0, 0
+
+		next := func(parent []int, idx *int) int {
+			for used[parent[*idx]] {
+				*idx++
+			}
+			return parent[*idx]
+		}
+
+		for k := range mask {
+			var v int
+			if mask[k] {
+				v = next(second, &secondIdx)
+			} else {
+				v = next(first, &firstIdx)
+			}
+			used[v] = true
+			child = append(child, v)
+		}
+		return child
+	}
+
+	return ppx(a, b), ppx(b, a)
+}
+
+// uniformOrderCrossover (UOBX) keeps the nodes of one parent at the
+// positions chosen by a random mask and fills the other positions with
+// the remaining nodes in the order of the other parent. The result is
+// repaired
+func uniformOrderCrossover(g *graph.Graph, a, b []int, rng *rand.Rand) ([]int, []int) {
+	mask := make([]bool, len(a))
+	for k := range mask {
+		mask[k] = rng.IntN(2) == 1
+	}
+
+	uobx := func(keep, donor []int) []int {
+		child := make([]int, len(keep))
+		used := make(map[int]bool)
+		for k := range keep {
+			if mask[k] {
+				child[k] = keep[k]
+				used[keep[k]] = true
+			}
+		}
+
+		donorIdx := 0
+		for k := range child {
+			if mask[k] {
+				continue
+			}
+			for used[donor[donorIdx]] {
+				donorIdx++
+			}
+			child[k] = donor[donorIdx]
+			used[donor[donorIdx]] = true
+		}
+		return repair(g, child)
+	}
+
+	return uobx(a, b), uobx(b, a)
+}
+
+// partiallyMappedCrossover (PMX) copies the segment between two cut points
+// of one parent and takes every other position from the other parent,
+// following the mapping defined by the segment to resolve duplicates.
+// The result is repaired
+func partiallyMappedCrossover(g *graph.Graph, a, b []int, rng *rand.Rand) ([]int, []int) {
+	i, j := cutPoints(len(a), rng)
+
+	pmx := func(keep, donor []int) []int {
+		child := make([]int, len(keep))
+		mapping := make(map[int]int)
+		for k := i; k < j; k++ {
+			child[k] = keep[k]
+			mapping[keep[k]] = donor[k]
+		}
+
+		for k := range child {
+			if k >= i && k < j {
+				continue
+			}
+			v := donor[k]
+			for {
+				mapped, ok := mapping[v]
+				if !ok {
+					break
+				}
+				v = mapped
+			}
+			child[k] = v
+		}
+		return repair(g, child)
+	}
+
+	return pmx(a, b), pmx(b, a)
+}
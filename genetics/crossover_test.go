@@ -0,0 +1,42 @@
+package genetics
+
+import (
+	"testing"
+
+	"github.com/andey-robins/magical/parsers/blif"
+	"github.com/andey-robins/magical/sequence"
+)
+
+func TestCrossoverOperatorsPreservePrecedence(t *testing.T) {
+	g := blif.LoadBlifAsGraph("../input/circuits/cm150a_128.blif")
+	rng := NewRNG(1).Rand
+
+	for _, name := range CrossoverNames() {
+		op, ok := GetCrossover(name)
+		if !ok {
+			t.Fatalf("Expected crossover operator %s to exist", name)
+		}
+
+		for i := 0; i < 20; i++ {
+			a := g.SynthesizeRandomValidSequence(rng.Int()).GetSequence()
+			b := g.SynthesizeRandomValidSequence(rng.Int()).GetSequence()
+
+			childOne, childTwo := op.Cross(g, a, b, rng)
+			for _, child := range [][]int{childOne, childTwo} {
+				if len(child) != len(a) {
+					t.Errorf("%s: expected child of length %d, got %d", name, len(a), len(child))
+				}
+				seen := make(map[int]bool)
+				for _, v := range child {
+					if seen[v] {
+						t.Errorf("%s: produced a duplicate node %d", name, v)
+					}
+					seen[v] = true
+				}
+				if !g.IsValidSequence(sequence.NewSequence(child)) {
+					t.Errorf("%s: produced an invalid sequence %v", name, child)
+				}
+			}
+		}
+	}
+}
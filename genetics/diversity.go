@@ -0,0 +1,145 @@
+package genetics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/andey-robins/magical/graph"
+)
+
+// Dedup strategies replace genes whose sequence is already in the
+// population at the end of each generation, which keeps a converged
+// population from filling with copies of the same sequence. Random
+// replaces a duplicate with a fresh random gene and mutate replaces it
+// with a heavily mutated copy.
+const (
+	DedupNone   = "none"
+	DedupRandom = "random"
+	DedupMutate = "mutate"
+)
+
+// dedupMutations is the number of mutations applied to a duplicate
+const dedupMutations = 10
+
+// DedupNames returns the names of every available dedup strategy
+func DedupNames() []string {
+	return []string{DedupNone, DedupRandom, DedupMutate}
+}
+
+// Diversity measures how different the genes of a population are.
+// Distance is the mean distance between the positions of a node in two
+// genes, scaled by the length of the sequences, and Entropy is the mean
+// entropy of the positions of a node across the population, scaled by its
+// largest possible value. Both are 0 when every gene is the same.
+type Diversity struct {
+	Unique   int     `json:"unique"` // the number of distinct sequences
+	Distance float64 `json:"distance"`
+	Entropy  float64 `json:"entropy"`
+}
+
+// MeasureDiversity measures the diversity of `genes`, which must all be
+// sequences of the same graph. It takes time proportional to the size of
+// the population times the length of the sequences, rather than comparing
+// every pair of genes
+func MeasureDiversity(genes []*Gene) Diversity {
+	d := Diversity{Unique: uniqueGenes(genes)}
+	if len(genes) < 2 {
+		return d
+	}
+
+	positions := make(map[int][]int)
+	for _, gene := range genes {
+		for i, id := range gene.Sequence.GetSequence() {
+			positions[id] = append(positions[id], i)
+		}
+	}
+	length := len(genes[0].Sequence.GetSequence())
+	if length < 2 {
+		return d
+	}
+
+	// nodes are visited in the order of the first gene so that the sums
+	// are the same every time
+	maxEntropy := math.Log(float64(min(len(genes), length)))
+	for _, id := range genes[0].Sequence.GetSequence() {
+		pos := positions[id]
+		sort.Ints(pos)
+		n := len(pos)
+
+		// with sorted positions, the k-th position is larger than the k
+		// before it and smaller than the n-k-1 after it
+		sum := 0
+		for k, x := range pos {
+			sum += x * (2*k - n + 1)
+		}
+		d.Distance += float64(sum) / float64(n*(n-1)/2)
+
+		entropy := 0.0
+		for start := 0; start < n; {
+			end := start
+			for end < n && pos[end] == pos[start] {
+				end++
+			}
+			p := float64(end-start) / float64(n)
+			entropy -= p * math.Log(p)
+			start = end
+		}
+		if maxEntropy > 0 {
+			d.Entropy += entropy / maxEntropy
+		}
+	}
+
+	d.Distance /= float64(len(positions)) * float64(length-1)
+	d.Entropy /= float64(len(positions))
+	return d
+}
+
+// dedup replaces every gene whose sequence appeared earlier in the
+// population according to the population's dedup strategy. The elite are
+// never replaced. The new genes are evaluated with the rest of the
+// population next generation
+//
+// This function uses random numbers, but pulls from p.RNG which is seeded
+// deterministically and doesn't spawn any go-routines
+func (p *GA) dedup(g *graph.Graph) {
+	if p.Dedup == "" || p.Dedup == DedupNone {
+		return
+	}
+
+	seen := make(map[string]bool)
+	for i, gene := range p.Genes {
+		key := fmt.Sprint(gene.Sequence.GetSequence())
+		if !seen[key] || i < p.Elitism {
+			seen[key] = true
+			continue
+		}
+
+		switch p.Dedup {
+		case DedupRandom:
+			p.Genes[i] = &Gene{Sequence: g.SynthesizeRandomValidSequence(p.RNG.Int())}
+		case DedupMutate:
+			seq := gene.Sequence
+			for j := 0; j < dedupMutations; j++ {
+				seq = p.pickMutation()(g, seq, p.RNG.Int())
+			}
+			p.Genes[i] = &Gene{Sequence: seq}
+		default:
+			panic("unknown dedup strategy: " + p.Dedup)
+		}
+	}
+}
+
+// describeDiversity formats the diversity for the epoch report
+func (p *GA) describeDiversity() string {
+	return fmt.Sprintf(" Unique: %d Distance: %.3f Entropy: %.3f", p.Diversity.Unique, p.Diversity.Distance, p.Diversity.Entropy)
+}
+
+// uniqueGenes counts the distinct sequences of `genes`
+func uniqueGenes(genes []*Gene) int {
+	seen := make(map[string]bool)
+	for _, gene := range genes {
+		seen[fmt.Sprint(gene.Sequence.GetSequence())] = true
+	}
+	return len(seen)
+}
@@ -0,0 +1,84 @@
+package genetics
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/andey-robins/magical/graph"
+	"github.com/andey-robins/magical/parsers/blif"
+	"github.com/andey-robins/magical/sequence"
+)
+
+func genesOf(seqs ...[]int) []*Gene {
+	genes := make([]*Gene, len(seqs))
+	for i, seq := range seqs {
+		genes[i] = &Gene{Sequence: sequence.NewSequence(seq)}
+	}
+	return genes
+}
+
+func TestMeasureDiversity(t *testing.T) {
+	tests := []struct {
+		genes    []*Gene
+		expected Diversity
+	}{
+		// identical genes have no diversity
+		{genesOf([]int{1, 2, 3}, []int{1, 2, 3}), Diversity{1, 0, 0}},
+		// 1 and 3 are 2 apart and 2 stays put, so the mean distance is 4/3
+		// out of the largest distance of 2
+		{genesOf([]int{1, 2, 3}, []int{3, 2, 1}), Diversity{2, 2.0 / 3, 2.0 / 3}},
+		// every node is in every position once, so two genes put a node 4/3
+		// apart on average
+		{genesOf([]int{1, 2, 3}, []int{2, 3, 1}, []int{3, 1, 2}), Diversity{3, 2.0 / 3, 1}},
+	}
+
+	for _, test := range tests {
+		d := MeasureDiversity(test.genes)
+		if d.Unique != test.expected.Unique ||
+			math.Abs(d.Distance-test.expected.Distance) > 1e-9 ||
+			math.Abs(d.Entropy-test.expected.Entropy) > 1e-9 {
+			t.Errorf("expected diversity %+v, got %+v", test.expected, d)
+		}
+	}
+}
+
+func TestDedup(t *testing.T) {
+	g := blif.LoadBlifAsGraph("../input/circuits/5xp1_90.blif")
+
+	for _, strategy := range []string{DedupRandom, DedupMutate} {
+		pop := NewGA(10, 1, 0.2, g, 1, 0, "")
+		pop.Dedup = strategy
+		pop.Elitism = 2
+
+		// the second elite is kept even though it is a copy of the first
+		for i := range pop.Genes {
+			clone := *pop.Genes[0]
+			pop.Genes[i] = &clone
+		}
+		pop.dedup(g)
+
+		elite := fmt.Sprint(pop.Genes[0].Sequence.GetSequence())
+		for i, gene := range pop.Genes {
+			if !g.IsValidSequence(gene.Sequence) {
+				t.Errorf("%s: dedup produced an invalid sequence", strategy)
+			}
+			if copied := fmt.Sprint(gene.Sequence.GetSequence()) == elite; copied != (i < 2) {
+				t.Errorf("%s: expected only the 2 elite genes to be copies, gene %d is wrong", strategy, i)
+			}
+		}
+	}
+}
+
+func TestDiversityIsReported(t *testing.T) {
+	g := graph.LoadGraphFromString("Inputs 3\n1 2 3\nOutputs 1\n4\nNodes 4\nEdges 6\n1 5\n2 7\n3 6\n5 7\n6 4\n7 4")
+
+	pop := NewGA(10, 1, 0.2, g, 1, 0, "")
+	pop.SynchronizeRNG()
+	pop.evaluation(g)
+	pop.execute()
+
+	if pop.Diversity.Unique < 1 || pop.Diversity.Distance < 0 || pop.Diversity.Entropy < 0 || pop.Diversity.Entropy > 1 {
+		t.Errorf("unexpected diversity %+v", pop.Diversity)
+	}
+}
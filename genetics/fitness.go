@@ -0,0 +1,73 @@
+package genetics
+
+import (
+	"fmt"
+
+	"github.com/andey-robins/magical/graph"
+	"github.com/andey-robins/magical/memory"
+	"github.com/andey-robins/magical/sequence"
+)
+
+// Tie-breakers are secondary fitness measures used to order genes
+// which share the same peak footprint. Lower is better for all of them.
+const (
+	TieBreakerNone     = "none"
+	TieBreakerArea     = "area"
+	TieBreakerPeakTime = "peak-time"
+)
+
+// TieBreakers returns the names of every available tie-breaker
+func TieBreakers() []string {
+	return []string{TieBreakerNone, TieBreakerArea, TieBreakerPeakTime}
+}
+
+// secondaryFitness will compute the tie-breaking measure named by `tieBreaker`
+// from a completed simulation. An empty name is treated as TieBreakerNone
+// so that populations loaded from older checkpoints behave as they did
+func secondaryFitness(tieBreaker string, mem *memory.Memory) int {
+	switch tieBreaker {
+	case TieBreakerArea:
+		return mem.GetAreaUnderCurve()
+	case TieBreakerPeakTime:
+		return mem.GetTimeAtPeak()
+	default:
+		return 0
+	}
+}
+
+// Evaluate simulates `seq` over `g` and returns the peak footprint along
+// with the secondary fitness selected by `tieBreaker`
+func Evaluate(g *graph.Graph, seq *sequence.Sequence, tieBreaker string) (int, int, error) {
+	mem, err := g.SimulateSequence(seq)
+	if err != nil {
+		return 0, 0, err
+	}
+	return mem.GetMaxUtilization(), secondaryFitness(tieBreaker, mem), nil
+}
+
+// Less orders two genes lexicographically, first by peak footprint and
+// then by the secondary fitness
+func Less(a, b *Gene) bool {
+	if a.Fitness != b.Fitness {
+		return a.Fitness < b.Fitness
+	}
+	return a.Secondary < b.Secondary
+}
+
+// evaluate simulates `seq` with the population's tie-breaker, see Evaluate
+func (p *GA) evaluate(g *graph.Graph, seq *sequence.Sequence) (int, int, error) {
+	return Evaluate(g, seq, p.TieBreaker)
+}
+
+// less orders two genes, see Less
+func (p *GA) less(a, b *Gene) bool {
+	return Less(a, b)
+}
+
+// describeFitness formats a gene's fitness for the epoch report
+func (p *GA) describeFitness(gene *Gene) string {
+	if p.TieBreaker == "" || p.TieBreaker == TieBreakerNone {
+		return fmt.Sprintf("%d", gene.Fitness)
+	}
+	return fmt.Sprintf("%d (%s %d)", gene.Fitness, p.TieBreaker, gene.Secondary)
+}
@@ -0,0 +1,43 @@
+package genetics
+
+import (
+	"testing"
+
+	"github.com/andey-robins/magical/graph"
+	"github.com/andey-robins/magical/sequence"
+)
+
+func TestTieBreakerOrdersEqualFitness(t *testing.T) {
+	g := graph.LoadGraphFromString("Inputs 3\n1 2 3\nOutputs 1\n4\nNodes 4\nEdges 6\n1 5\n2 7\n3 6\n5 7\n6 4\n7 4")
+
+	pop := NewGA(4, 1, 0.1, g, 1, 0, "")
+	pop.TieBreaker = TieBreakerArea
+
+	// both sequences peak at 4 cells, but the second keeps fewer cells
+	// alive along the way
+	wide := sequence.NewSequence([]int{5, 6, 7, 4})
+	narrow := sequence.NewSequence([]int{5, 7, 6, 4})
+
+	wideFitness, wideArea, err := pop.evaluate(g, wide)
+	if err != nil {
+		t.Fatal(err)
+	}
+	narrowFitness, narrowArea, err := pop.evaluate(g, narrow)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if wideFitness != narrowFitness {
+		t.Fatalf("Expected equal fitness, got %d and %d", wideFitness, narrowFitness)
+	}
+
+	if !pop.less(&Gene{narrow, narrowFitness, narrowArea}, &Gene{wide, wideFitness, wideArea}) {
+		t.Errorf("Expected area %d to be preferred over area %d", narrowArea, wideArea)
+	}
+
+	pop.TieBreaker = TieBreakerNone
+	_, secondary, _ := pop.evaluate(g, narrow)
+	if secondary != 0 {
+		t.Errorf("Expected no secondary fitness without a tie-breaker, got %d", secondary)
+	}
+}
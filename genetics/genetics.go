@@ -1,13 +1,11 @@
 package genetics
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"math"
-	"math/rand"
-	"os"
 	"sort"
-	"sync"
 
 	"github.com/andey-robins/magical/checkpoint"
 	"github.com/andey-robins/magical/graph"
@@ -15,30 +13,98 @@ import (
 )
 
 type Gene struct {
-	Sequence *sequence.Sequence `json:"sequence"`
-	Fitness  int                `json:"fitness"`
+	Sequence  *sequence.Sequence `json:"sequence"`
+	Fitness   int                `json:"fitness"`
+	Secondary int                `json:"secondary"` // breaks ties between equal fitness, see TieBreaker
 }
 
 type GA struct {
-	Genes          []*Gene `json:"genes"`
-	BestFitness    int     `json:"bestFitness"`
-	BestGene       *Gene   `json:"bestGene"`
-	AvgFitness     float64 `json:"avgFitness"`
-	Generations    int     `json:"generations"`
-	Size           int     `json:"size"`
-	MutationChance float64 `json:"mutationChance"`
-	Seed           int     `json:"seed"`
-	rng            *rand.Rand
+	Genes          []*Gene   `json:"genes"`
+	BestFitness    int       `json:"bestFitness"`
+	BestGene       *Gene     `json:"bestGene"`
+	AvgFitness     float64   `json:"avgFitness"`
+	MedianFitness  float64   `json:"medianFitness"`
+	WorstFitness   int       `json:"worstFitness"`
+	Diversity      Diversity `json:"diversity"` // of the population evaluated last generation
+	Generations    int       `json:"generations"`
+	Size           int       `json:"size"`
+	MutationChance float64   `json:"mutationChance"`
+	Seed           int       `json:"seed"`
+	RNG            *RNG      `json:"rng"`        // drawn from on the goroutine running Evolve only
+	TieBreaker     string    `json:"tieBreaker"` // one of TieBreakers(), empty is TieBreakerNone
+	Crossover      string    `json:"crossover"`  // one of CrossoverNames()
+	CrossoverRate  float64   `json:"crossoverRate"`
+
+	// Selection is one of SelectionNames(). TournamentSize and TruncationRatio
+	// only apply to their strategy. The best Elitism genes are carried into
+	// the next generation unchanged and are never mutated
+	Selection       string  `json:"selection"`
+	TournamentSize  int     `json:"tournamentSize"`
+	TruncationRatio float64 `json:"truncationRatio"`
+	Elitism         int     `json:"elitism"`
+
+	// MutationOperators weighs the chance of each of MutationNames() being
+	// used for a mutation. When it's empty every mutation is a swap
+	MutationOperators map[string]float64 `json:"mutationOperators"`
+
+	// Adaptive control, see adapt. It is disabled when both StagnationWindow
+	// and RestartAfter are 0. Stagnation and MutationLevel are its state
+	StagnationWindow int     `json:"stagnationWindow"`
+	MutationBoost    float64 `json:"mutationBoost"`
+	MaxMutation      float64 `json:"maxMutation"`
+	ReseedFraction   float64 `json:"reseedFraction"`
+	RestartAfter     int     `json:"restartAfter"`
+	Stagnation       int     `json:"stagnation"`
+	MutationLevel    int     `json:"mutationLevel"`
+
+	// LocalSearch is one of LocalSearchNames(). It hill-climbs the best
+	// LocalSearchGenes genes each generation for at most LocalSearchSteps
+	// moves each, or until a local optimum when LocalSearchSteps is 0
+	LocalSearch      string `json:"localSearch"`
+	LocalSearchGenes int    `json:"localSearchGenes"`
+	LocalSearchSteps int    `json:"localSearchSteps"`
+
+	// Dedup is one of DedupNames(), which replaces duplicate genes at the
+	// end of each generation
+	Dedup string `json:"dedup"`
+
+	// CacheSize is the number of sequences whose fitness is remembered so
+	// they aren't simulated again, 0 to disable the cache
+	CacheSize int `json:"cacheSize"`
+
+	// Workers is the number of goroutines which evaluate and mutate genes,
+	// GOMAXPROCS when it is 0. It isn't checkpointed since it depends on the
+	// machine the search runs on
+	Workers int `json:"-"`
+
+	// Termination stops evolving early, see Termination
+	Termination
+
+	// Metadata describes the run in its checkpoints. It is only set on the
+	// top level population, not on the islands of an archipelago
+	checkpoint.Metadata
+
+	parents   []*Gene // the sorted population that parents are selected from
+	observers Observers
+	cache     *FitnessCache
+
+	// emigration is the number of genes an archipelago migrates from the
+	// population, copies of which execute keeps in fittest
+	emigration int
+	fittest    []*Gene
 
 	// the number of generations we will continue searching without improvements
-	Epsilon        int    `json:"epsilon"`
-	CheckpointFreq int    `json:"checkpointFreq"` // set to 0 to disable checkpoints
-	CheckpointPath string `json:"checkpointPath"`
+	Epsilon          int               `json:"epsilon"`
+	CheckpointFreq   int               `json:"checkpointFreq"` // set to 0 to disable checkpoints
+	CheckpointPath   string            `json:"checkpointPath"`
+	CheckpointPolicy checkpoint.Policy `json:"checkpointPolicy"`
 }
 
 // NewGA will create a new population of population size `size` with a mutation
 // chance of `mut` and epsilon `e`. It will seed the population with random valid sequences
-// and evaluate them.
+// and evaluate them. The population starts with truncation selection of the
+// best quarter, no elitism and the default crossover operator on every pairing;
+// set the exported fields before evolving to change this.
 func NewGA(size, e int, mut float64, graph *graph.Graph, seed int, checkpointFreq int, chkpath string) *GA {
 	genes := make([]*Gene, size)
 
@@ -46,7 +112,7 @@ func NewGA(size, e int, mut float64, graph *graph.Graph, seed int, checkpointFre
 	bestFitness := math.MaxInt
 	bestGene := &Gene{}
 
-	rng := rand.New(rand.NewSource(int64(seed)))
+	rng := NewRNG(seed)
 
 	for i := 0; i < size; i++ {
 		seq := graph.SynthesizeRandomValidSequence(rng.Int())
@@ -56,7 +122,7 @@ func NewGA(size, e int, mut float64, graph *graph.Graph, seed int, checkpointFre
 		}
 		fitness := mem.GetMaxUtilization()
 
-		genes[i] = &Gene{seq, fitness}
+		genes[i] = &Gene{seq, fitness, 0}
 
 		totalFitness += fitness
 		if fitness < bestFitness {
@@ -66,156 +132,200 @@ func NewGA(size, e int, mut float64, graph *graph.Graph, seed int, checkpointFre
 	}
 
 	return &GA{
-		Genes:          genes,
-		Epsilon:        e,
-		AvgFitness:     float64(totalFitness) / float64(size),
-		BestFitness:    bestFitness,
-		BestGene:       bestGene,
-		Generations:    0,
-		Size:           size,
-		MutationChance: mut,
-		Seed:           seed,
-		rng:            rng,
-		CheckpointFreq: checkpointFreq,
-		CheckpointPath: chkpath,
+		Genes:            genes,
+		Epsilon:          e,
+		AvgFitness:       float64(totalFitness) / float64(size),
+		BestFitness:      bestFitness,
+		BestGene:         bestGene,
+		Generations:      0,
+		Size:             size,
+		MutationChance:   mut,
+		Seed:             seed,
+		Crossover:        "default",
+		CrossoverRate:    1,
+		Selection:        SelectionTruncation,
+		TournamentSize:   2,
+		TruncationRatio:  0.25,
+		MutationBoost:    1.5,
+		MaxMutation:      1,
+		LocalSearch:      LocalSearchNone,
+		LocalSearchGenes: 1,
+		Dedup:            DedupNone,
+		RNG:              rng,
+		CheckpointFreq:   checkpointFreq,
+		CheckpointPath:   chkpath,
 	}
 }
 
 // Evolve will evolve the population until we have gone `epsilon` generations without
-// improving the best fitness
-func (p *GA) Evolve(g *graph.Graph) {
-	if p.rng == nil {
+// improving the best fitness, until one of the rules of p.Termination is met
+// or until `ctx` is cancelled. Cancellation is noticed between generations.
+// If a generation fails, for example because a gene's sequence is invalid,
+// the search stops without checkpointing it and the error is returned
+func (p *GA) Evolve(ctx context.Context, g *graph.Graph) error {
+	if p.RNG == nil {
 		p.SynchronizeRNG()
 	}
-
-	bestFitness := p.BestFitness
-	roundsWithoutImprovement := 0
-
-	reportEpoch := func() {
-		log.Printf("Epoch %d: Best fitness: %d Avg fitness: %v\n", p.Generations, p.BestFitness, p.AvgFitness)
-	}
-
-	checkpointFilename := func(p *GA) string {
-		return fmt.Sprintf("%s/%d.json", p.CheckpointPath, p.Generations)
-	}
-
-	if p.CheckpointFreq > 0 {
-		os.MkdirAll(p.CheckpointPath, 0755)
-		checkpoint.Save(checkpointFilename(p), p)
+	p.BeginSearch(g, p.BestFitness)
+
+	loop := Loop{
+		Unit:        "generation",
+		Termination: &p.Termination,
+		Observers:   p.observers,
+		Epsilon:     p.Epsilon,
+		Step: func() (bool, error) {
+			return p.step(g)
+		},
+		Stats: p.stats,
+		Best: func() *Gene {
+			return p.BestGene
+		},
+		Report: func() {
+			log.Printf("Epoch %d: Best fitness: %s Avg fitness: %v%s\n", p.Generations, p.describeFitness(p.BestGene), p.AvgFitness, p.describeDiversity()+p.describeCache()+p.describeAdaptive())
+		},
+		Checkpoint:       p,
+		CheckpointFreq:   p.CheckpointFreq,
+		CheckpointPath:   p.CheckpointPath,
+		CheckpointPolicy: p.CheckpointPolicy,
 	}
+	return loop.Run(ctx)
+}
 
-	for roundsWithoutImprovement < p.Epsilon {
-		p.nextEpoch(g)
-
-		if p.CheckpointFreq > 0 && p.Generations%p.CheckpointFreq == 0 {
-			checkpoint.Save(checkpointFilename(p), p)
-		}
-
-		if p.BestFitness < bestFitness {
-			roundsWithoutImprovement = 0
-			bestFitness = p.BestFitness
-		} else {
-			roundsWithoutImprovement++
-		}
-		reportEpoch()
+// step runs one generation followed by the adaptive control. It returns
+// true if the best fitness of the generation improved on p.Record
+func (p *GA) step(g *graph.Graph) (bool, error) {
+	if err := p.nextEpoch(g); err != nil {
+		return false, err
 	}
+	improved := p.Improve(p.BestFitness)
+	p.adapt(g, improved)
+	return improved, nil
 }
 
-func (p *GA) nextEpoch(g *graph.Graph) {
-	p.evaluation(g)
+func (p *GA) nextEpoch(g *graph.Graph) error {
+	if err := p.evaluation(g); err != nil {
+		return err
+	}
 	p.execute()
+	if err := p.localSearch(g); err != nil {
+		return err
+	}
 	p.crossover(g)
-	p.mutate(g)
+	if err := p.mutate(g); err != nil {
+		return err
+	}
+	p.dedup(g)
 	p.Generations++
+	return nil
 }
 
 // select will evaluate all of the genes in the population and update
 // the best gene and best fitness values accordingly. this is parallelized
-// using waitgroups since we can evaluate each gene independently
+// over p.Workers since we can evaluate each gene independently. Genes
+// whose sequence is in the fitness cache aren't simulated again
 //
-// This function is deterministic
-func (p *GA) evaluation(g *graph.Graph) {
+// This function is deterministic. The cache is only used from this
+// goroutine, in the order of the population
+func (p *GA) evaluation(g *graph.Graph) error {
+	pending := p.cachedFitness()
+
+	err := Parallel(p.Workers, len(pending), func(i int) error {
+		gene := pending[i]
+		if !g.IsValidSequence(gene.Sequence) {
+			return fmt.Errorf("invalid sequence %v", gene.Sequence.GetSequence())
+		}
+		fitness, secondary, err := p.evaluate(g, gene.Sequence)
+		if err != nil {
+			return err
+		}
+		gene.Fitness = fitness
+		gene.Secondary = secondary
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("evaluating genes: %w", err)
+	}
+	p.Evaluations += len(pending)
 
-	var wg sync.WaitGroup
-	wg.Add(len(p.Genes))
-	for _, gene := range p.Genes {
-		go func(gene *Gene, graph *graph.Graph) {
-			if !g.IsValidSequence(gene.Sequence) {
-				panic("Invalid sequence")
-			}
-			mem, err := graph.SimulateSequence(gene.Sequence)
-			if err != nil {
-				panic(err)
-			}
-			gene.Fitness = mem.GetMaxUtilization()
-			wg.Done()
-		}(gene, g)
+	if p.cache != nil {
+		for _, gene := range pending {
+			p.cache.Put(gene.Sequence, gene.Fitness, gene.Secondary)
+		}
 	}
-	wg.Wait()
+	return nil
 }
 
-// execute will cull the population down to the top 50% or less of genes breaking
-// ties randomly
+// execute will sort the population from best to worst and cull it down to
+// the genes which survive into the next generation, see survivors. Genes
+// with the same fitness are ordered by the secondary fitness of the
+// tie-breaker and otherwise ties are broken randomly
 //
 // This function is deterministic
 func (p *GA) execute() {
 	p.calculateStats()
 
 	sort.Slice(p.Genes, func(i, j int) bool {
-		return p.Genes[i].Fitness < p.Genes[j].Fitness
+		return p.less(p.Genes[i], p.Genes[j])
 	})
 
-	p.BestFitness = p.Genes[0].Fitness
-	p.BestGene = p.Genes[0]
+	// keep a copy of the best gene so that mutating the population
+	// can't change it out from under us
+	best := *p.Genes[0]
+	p.BestFitness = best.Fitness
+	p.BestGene = &best
+
+	// the population is bred before an archipelago migrates, so the genes
+	// which emigrate are copied while they are still the evaluated fittest
+	p.fittest = make([]*Gene, min(p.emigration, len(p.Genes)))
+	for i := range p.fittest {
+		gene := *p.Genes[i]
+		p.fittest[i] = &gene
+	}
 
-	p.Genes = p.Genes[:p.Size/4]
+	// truncation picks parents from the survivors while the other
+	// strategies pick from the whole population
+	survivors := append(make([]*Gene, 0, p.Size), p.Genes[:p.survivors()]...)
+	if p.Selection == "" || p.Selection == SelectionTruncation {
+		p.parents = survivors
+	} else {
+		p.parents = p.Genes
+	}
+	p.Genes = survivors
 }
 
-// crossover will select two genes from the population and combine them
-// at a random point to create two new genes. this is repeated until we
-// have a new population of the same size as the old population
+// crossover will select two parents with the selection strategy and combine them
+// with the population's crossover operator to create two new genes. With
+// probability 1 - CrossoverRate the parents are cloned instead. This is
+// repeated until we have a new population of the same size as the old population
 //
-// This function uses random numbers, but pulls from p.rng which is seeded
+// This function uses random numbers, but pulls from p.RNG which is seeded
 // deterministically and doesn't spawn any go-routines
 func (p *GA) crossover(g *graph.Graph) {
+	op, ok := GetCrossover(p.Crossover)
+	if !ok {
+		panic("unknown crossover operator: " + p.Crossover)
+	}
+
+	selector := p.selector()
 	for len(p.Genes) < p.Size {
-		// randomly select two genes and a crossover point
-		randGeneOne := p.Genes[p.rng.Intn(len(p.Genes))]
-		randGeneTwo := p.Genes[p.rng.Intn(len(p.Genes))]
-		crossoverPoint := p.rng.Intn(len(randGeneOne.Sequence.GetSequence()))
-
-		crossover := func(g1, g2 *Gene, pt int) *Gene {
-
-			genes := make([]int, 0)
-			genes = append(genes, g1.Sequence.GetSequence()[:pt]...)
-
-			in := func(ll []int, v int) bool {
-				for _, vv := range ll {
-					if vv == v {
-						return true
-					}
-				}
-				return false
-			}
-
-			for _, v := range g2.Sequence.GetSequence() {
-				if !in(genes, v) {
-					genes = append(genes, v)
-				}
-			}
-
-			return &Gene{
-				Sequence: sequence.NewSequence(genes),
-				Fitness:  0,
-			}
+		// select two parents
+		randGeneOne := selector.Select(p.parents, p.RNG.Rand)
+		randGeneTwo := selector.Select(p.parents, p.RNG.Rand)
+
+		// create the new genes. we don't evaluate them yet since that'll happen in the next epoch.
+		// a rate of 1 skips the draw so that older runs replay the same random stream
+		var childOne, childTwo []int
+		if p.crossoverRate() < 1 && p.RNG.Float64() >= p.crossoverRate() {
+			childOne, childTwo = randGeneOne.Sequence.GetSequence(), randGeneTwo.Sequence.GetSequence()
+		} else {
+			childOne, childTwo = op.Cross(g, randGeneOne.Sequence.GetSequence(), randGeneTwo.Sequence.GetSequence(), p.RNG.Rand)
 		}
 
-		// create the new genes. we don't evaluate them yet since that'll happen in the next epoch
-		newGeneOne := crossover(randGeneOne, randGeneTwo, crossoverPoint)
-		newGeneTwo := crossover(randGeneTwo, randGeneOne, crossoverPoint)
-
 		// put them in the population
-		p.Genes = append(p.Genes, newGeneOne, newGeneTwo)
+		p.Genes = append(p.Genes,
+			&Gene{Sequence: sequence.NewSequence(childOne)},
+			&Gene{Sequence: sequence.NewSequence(childTwo)},
+		)
 	}
 
 	// if we have too many genes, cull
@@ -224,24 +334,55 @@ func (p *GA) crossover(g *graph.Graph) {
 	}
 }
 
-// mutate will randomly select a gene from the population and randomly
-// swap two of the elements in the sequence sometimes
+// crossoverRate returns the probability of crossing two parents rather
+// than cloning them. Checkpoints from before crossover was configurable
+// have no operator recorded and always crossed
+func (p *GA) crossoverRate() float64 {
+	if p.Crossover == "" {
+		return 1
+	}
+	return p.CrossoverRate
+}
+
+// mutate will mutate each gene except for the elite, which sit at the front
+// of the population, with probability MutationChance. Each mutation uses an
+// operator picked by weight from MutationOperators. Adaptive control may raise
+// the chance and apply the operator more than once, see mutationChance
 //
-// This function generates random numbers at the time we invoke
-// each go-routine and then each routine seeds itself. This prevents
-// a race condition preventing determinism that was present in an earlier
-// version of this method
-func (p *GA) mutate(g *graph.Graph) {
-	var wg sync.WaitGroup
-	wg.Add(len(p.Genes))
-	for _, gene := range p.Genes {
-		go func(gene *Gene, seed int, g *graph.Graph) {
-			gene.Sequence = g.SmartMutate(gene.Sequence, seed)
+// This function draws the operator and seed of every mutation from p.RNG
+// before any gene is mutated and each worker seeds the mutation itself.
+// This prevents a race condition preventing determinism that was present
+// in an earlier version of this method
+func (p *GA) mutate(g *graph.Graph) error {
+	elites := min(p.Elitism, len(p.Genes))
+
+	chance, steps := p.mutationChance(), p.mutationSteps()
+
+	type mutation struct {
+		gene *Gene
+		op   MutationOperator
+		seed int
+	}
+	mutations := make([]mutation, 0, len(p.Genes)-elites)
+	for _, gene := range p.Genes[elites:] {
+		if p.RNG.Float64() >= chance {
+			continue
+		}
+		op := p.pickMutation()
+		mutations = append(mutations, mutation{gene, op, p.RNG.Int()})
+	}
 
-			wg.Done()
-		}(gene, p.rng.Int(), g)
+	err := Parallel(p.Workers, len(mutations), func(i int) error {
+		m := mutations[i]
+		for j := 0; j < steps; j++ {
+			m.gene.Sequence = m.op(g, m.gene.Sequence, m.seed+j)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("mutating genes: %w", err)
 	}
-	wg.Wait()
+	return nil
 }
 
 // GetBest will return the best gene in the population. If there are no valid
@@ -249,15 +390,16 @@ func (p *GA) mutate(g *graph.Graph) {
 // the best fitness and the best sequence
 func (p *GA) GetBest(g *graph.Graph) (int, *sequence.Sequence) {
 	for _, gene := range p.Genes {
-		mem, err := g.SimulateSequence(gene.Sequence)
+		fitness, secondary, err := p.evaluate(g, gene.Sequence)
 		if err != nil {
 			panic(err)
 		}
-		gene.Fitness = mem.GetMaxUtilization()
+		gene.Fitness = fitness
+		gene.Secondary = secondary
 	}
 
 	sort.Slice(p.Genes, func(i, j int) bool {
-		return p.Genes[i].Fitness < p.Genes[j].Fitness && p.Genes[i].Fitness != 0
+		return p.less(p.Genes[i], p.Genes[j]) && p.Genes[i].Fitness != 0
 	})
 
 	for _, gene := range p.Genes {
@@ -272,17 +414,28 @@ func (p *GA) GetBest(g *graph.Graph) (int, *sequence.Sequence) {
 
 func (p *GA) calculateStats() {
 	totalFitness := 0
+	fitnesses := make([]int, 0, len(p.Genes))
 	for _, gene := range p.Genes {
 		if gene.Fitness != 0 {
 			totalFitness += gene.Fitness
+			fitnesses = append(fitnesses, gene.Fitness)
 		}
 	}
 	p.AvgFitness = float64(totalFitness) / float64(p.Size)
+	p.Diversity = MeasureDiversity(p.Genes)
+
+	if len(fitnesses) == 0 {
+		return
+	}
+	sort.Ints(fitnesses)
+	n := len(fitnesses)
+	p.MedianFitness = float64(fitnesses[(n-1)/2]+fitnesses[n/2]) / 2
+	p.WorstFitness = fitnesses[n-1]
 }
 
 // SynchronizeRNG will reseed the random number generator for the population
-// so that we can reproduce the same results. This *MUST* be used when restarting
-// from a saved checkpoint
+// from its seed. Checkpoints save the state of the generator, so this is only
+// needed for checkpoints from before they did, which Evolve does itself
 func (p *GA) SynchronizeRNG() {
-	p.rng = rand.New(rand.NewSource(int64(p.Seed)))
+	p.RNG = NewRNG(p.Seed)
 }
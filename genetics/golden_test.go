@@ -0,0 +1,212 @@
+package genetics_test
+
+// The golden runs guard the determinism of every optimizer. Each run
+// evolves a graph from input/ with a fixed seed and is compared with the
+// trajectory and best sequence recorded in testdata/golden. The trajectory
+// includes a hash of the whole population after each generation, so a
+// change to any gene is caught even if it doesn't change the fitness. A change which
+// alters a run on purpose, such as a new random number generator, re-blesses
+// them with
+//
+//	go test ./genetics -run TestGoldenRuns -update
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/andey-robins/magical/genetics"
+	"github.com/andey-robins/magical/graph"
+	"github.com/andey-robins/magical/optimize"
+	"github.com/andey-robins/magical/parsers/blif"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden runs in testdata/golden")
+
+// a goldenRun is the record of a run which must not change
+type goldenRun struct {
+	Generations []goldenGeneration `json:"generations"`
+	BestFitness int                `json:"bestFitness"`
+	Sequence    []int              `json:"sequence"`
+	StopReason  string             `json:"stopReason"`
+}
+
+type goldenGeneration struct {
+	Generation  int     `json:"generation"`
+	BestFitness int     `json:"best"`
+	AvgFitness  float64 `json:"average"`
+	Population  string  `json:"population"` // see hashPopulation
+}
+
+// goldenRecorder records the trajectory of the run of `p`
+type goldenRecorder struct {
+	genetics.BaseObserver
+	p   optimize.Optimizer
+	run goldenRun
+}
+
+func (r *goldenRecorder) OnGeneration(stats genetics.Stats) {
+	r.run.Generations = append(r.run.Generations, goldenGeneration{stats.Generation, stats.BestFitness, stats.AvgFitness, hashPopulation(r.p)})
+}
+
+// hashPopulation hashes the sequence and fitness of every gene of `p` in
+// order, which are those of every island of an archipelago and the current
+// sequence of a search without a population
+func hashPopulation(p optimize.Optimizer) string {
+	var genes []*genetics.Gene
+	switch p := p.(type) {
+	case *genetics.GA:
+		genes = p.Genes
+	case *genetics.Archipelago:
+		for _, island := range p.Islands {
+			genes = append(genes, island.Genes...)
+		}
+	case *optimize.Annealing:
+		genes = []*genetics.Gene{p.Current}
+	case *optimize.Tabu:
+		genes = []*genetics.Gene{p.Current}
+	default:
+		panic(fmt.Sprintf("no population to hash for %T", p))
+	}
+
+	h := fnv.New64a()
+	for _, gene := range genes {
+		binary.Write(h, binary.LittleEndian, []uint64{genetics.HashSequence(gene.Sequence), uint64(gene.Fitness), uint64(gene.Secondary)})
+	}
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+func (r *goldenRecorder) OnFinish(stats genetics.Stats) {
+	r.run.StopReason = stats.StopReason
+}
+
+var goldenRuns = []struct {
+	name  string
+	graph string
+	new   func(g *graph.Graph) optimize.Optimizer
+}{
+	{"ga", "5xp1_90.blif", func(g *graph.Graph) optimize.Optimizer {
+		p := genetics.NewGA(40, 10, 0.2, g, 1, 0, "")
+		p.MaxGenerations = 30
+		return p
+	}},
+	{"ga-operators", "cm150a_128.blif", func(g *graph.Graph) optimize.Optimizer {
+		p := genetics.NewGA(40, 10, 0.5, g, 2, 0, "")
+		p.TieBreaker = genetics.TieBreakerArea
+		p.Crossover = "order"
+		p.CrossoverRate = 0.9
+		p.Selection = genetics.SelectionTournament
+		p.TournamentSize = 3
+		p.Elitism = 2
+		p.MutationOperators = map[string]float64{"swap": 1, "insert": 1, "block": 1, "reverse": 1}
+		p.StagnationWindow = 3
+		p.ReseedFraction = 0.1
+		p.Dedup = genetics.DedupMutate
+		p.CacheSize = 100
+		p.MaxGenerations = 30
+		return p
+	}},
+	{"ga-localsearch", "cm150a_128.blif", func(g *graph.Graph) optimize.Optimizer {
+		p := genetics.NewGA(20, 10, 0.2, g, 3, 0, "")
+		p.LocalSearch = genetics.LocalSearchSteepest
+		p.LocalSearchGenes = 2
+		p.LocalSearchSteps = 3
+		p.MaxGenerations = 10
+		return p
+	}},
+	{"islands", "cm150a_128.blif", func(g *graph.Graph) optimize.Optimizer {
+		seeds := genetics.IslandSeeds(4, 3)
+		islands := make([]*genetics.GA, len(seeds))
+		for i, seed := range seeds {
+			islands[i] = genetics.NewGA(20, 10, 0.3, g, seed, 0, "")
+			islands[i].Elitism = 1
+		}
+		a := genetics.NewArchipelago(islands, genetics.TopologyRing, 3, 2, 4, 10, 0, "")
+		a.MaxGenerations = 20
+		return a
+	}},
+	{"annealing", "5xp1_90.blif", func(g *graph.Graph) optimize.Optimizer {
+		a := optimize.NewAnnealing(50, 20, g, 5, 0, "")
+		a.MaxGenerations = 30
+		return a
+	}},
+	{"tabu", "cm150a_128.blif", func(g *graph.Graph) optimize.Optimizer {
+		t := optimize.NewTabu(10, g, 6, 0, "")
+		t.Candidates = 20
+		t.MaxGenerations = 30
+		return t
+	}},
+}
+
+func TestGoldenRuns(t *testing.T) {
+	for _, test := range goldenRuns {
+		t.Run(test.name, func(t *testing.T) {
+			g := blif.LoadBlifAsGraph(filepath.Join("..", "input", "circuits", test.graph))
+			p := test.new(g)
+			recorder := &goldenRecorder{p: p}
+			p.AddObserver(recorder)
+
+			if err := p.Evolve(context.Background(), g); err != nil {
+				t.Fatal(err)
+			}
+			run := recorder.run
+			fitness, seq := p.GetBest(g)
+			run.BestFitness, run.Sequence = fitness, seq.GetSequence()
+
+			path := filepath.Join("testdata", "golden", test.name+".json")
+			if *update {
+				writeGolden(t, path, run)
+				return
+			}
+			compareGolden(t, path, run)
+		})
+	}
+}
+
+func writeGolden(t *testing.T, path string, run goldenRun) {
+	bytes, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, append(bytes, '\n'), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("updated %s", path)
+}
+
+func compareGolden(t *testing.T, path string, run goldenRun) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v, run with -update to create it", err)
+	}
+	var want goldenRun
+	if err := json.Unmarshal(bytes, &want); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < min(len(run.Generations), len(want.Generations)); i++ {
+		if run.Generations[i] != want.Generations[i] {
+			t.Fatalf("generation %d differs from %s: got %+v, want %+v", want.Generations[i].Generation, path, run.Generations[i], want.Generations[i])
+		}
+	}
+	if len(run.Generations) != len(want.Generations) {
+		t.Fatalf("expected %d generations as in %s, got %d", len(want.Generations), path, len(run.Generations))
+	}
+	if run.BestFitness != want.BestFitness || run.StopReason != want.StopReason {
+		t.Errorf("expected best fitness %d stopped by %s as in %s, got %d stopped by %s",
+			want.BestFitness, want.StopReason, path, run.BestFitness, run.StopReason)
+	}
+	if !reflect.DeepEqual(run.Sequence, want.Sequence) {
+		t.Errorf("expected the best sequence in %s, got %v", path, run.Sequence)
+	}
+}
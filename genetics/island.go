@@ -0,0 +1,346 @@
+package genetics
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/andey-robins/magical/checkpoint"
+	"github.com/andey-robins/magical/graph"
+	"github.com/andey-robins/magical/sequence"
+)
+
+// Migration topologies describe which islands send their best genes to
+// which. In a ring each island sends to the next one, and in a fully
+// connected archipelago each island sends to every other island.
+const (
+	TopologyRing = "ring"
+	TopologyFull = "full"
+)
+
+// Topologies returns the names of every available migration topology
+func Topologies() []string {
+	return []string{TopologyRing, TopologyFull}
+}
+
+// An Archipelago is an island model genetic algorithm. Each island is an
+// independent GA with its own seed and parameters, and every
+// MigrationInterval generations copies of the best Migrants genes of each
+// island's last generation replace the newest genes of its neighbors in the
+// topology.
+type Archipelago struct {
+	Islands           []*GA  `json:"islands"`
+	Topology          string `json:"topology"`
+	MigrationInterval int    `json:"migrationInterval"`
+	Migrants          int    `json:"migrants"`
+	Seed              int    `json:"seed"`
+	TieBreaker        string `json:"tieBreaker"` // which every island shares, see NewArchipelago
+	Generations       int    `json:"generations"`
+	BestFitness       int    `json:"bestFitness"`
+	BestGene          *Gene  `json:"bestGene"`
+
+	// Termination stops evolving early, see Termination. Its Evaluations
+	// are the total of every island's
+	Termination
+
+	// Metadata describes the run in its checkpoints
+	checkpoint.Metadata
+
+	// the number of generations we will continue searching without improvements
+	Epsilon          int               `json:"epsilon"`
+	CheckpointFreq   int               `json:"checkpointFreq"` // set to 0 to disable checkpoints
+	CheckpointPath   string            `json:"checkpointPath"`
+	CheckpointPolicy checkpoint.Policy `json:"checkpointPolicy"`
+
+	// Workers is the number of goroutines evaluating and mutating genes,
+	// GOMAXPROCS when it is 0, which are shared evenly between the
+	// islands. It isn't checkpointed
+	Workers int `json:"-"`
+
+	observers Observers
+}
+
+// IslandSeeds derives `n` island seeds from the master seed `seed` so that
+// an archipelago is reproducible from a single seed
+func IslandSeeds(seed, n int) []int {
+	rng := NewRNG(seed)
+	seeds := make([]int, n)
+	for i := range seeds {
+		seeds[i] = rng.Int()
+	}
+	return seeds
+}
+
+// NewArchipelago will group the populations in `islands` into an island
+// model with the given migration topology, interval and number of migrants.
+// The islands' own checkpoints are disabled since the archipelago saves all
+// of them together, and the archipelago stops after `epsilon` generations
+// without improving on the best fitness of any island. Every island must
+// have the same tie-breaker so that their genes can be compared.
+func NewArchipelago(islands []*GA, topology string, interval, migrants, seed, epsilon, checkpointFreq int, chkpath string) *Archipelago {
+	a := &Archipelago{
+		Islands:           islands,
+		Topology:          topology,
+		MigrationInterval: interval,
+		Migrants:          migrants,
+		Seed:              seed,
+		Epsilon:           epsilon,
+		CheckpointFreq:    checkpointFreq,
+		CheckpointPath:    chkpath,
+	}
+
+	for _, island := range islands {
+		island.CheckpointFreq = 0
+	}
+	if len(islands) > 0 {
+		a.TieBreaker = islands[0].TieBreaker
+	}
+	if err := a.checkTieBreakers(); err != nil {
+		panic(err)
+	}
+	a.recordBest()
+
+	return a
+}
+
+// Evolve will evolve every island concurrently until we have gone `epsilon`
+// generations without improving the best fitness across all islands,
+// until one of the rules of a.Termination is met or until `ctx` is
+// cancelled. The islands' own rules are ignored.
+//
+// Each island only draws from its own random number generator, and
+// migration happens after every island has finished the generation, so
+// the result doesn't depend on how the islands are scheduled. If an island
+// fails the search stops without checkpointing the generation and the
+// error of the first island which failed is returned
+func (a *Archipelago) Evolve(ctx context.Context, g *graph.Graph) error {
+	for _, island := range a.Islands {
+		if island.RNG == nil {
+			island.SynchronizeRNG()
+		}
+		island.Workers = max(1, WorkerCount(a.Workers)/len(a.Islands))
+		island.emigration = a.Migrants
+		// each island tracks improvements against its own best for its adaptive control
+		if island.Record == 0 {
+			island.Record = island.BestFitness
+		}
+	}
+	// archipelagos from before the tie-breaker was recorded take their islands'
+	if a.TieBreaker == "" && len(a.Islands) > 0 {
+		a.TieBreaker = a.Islands[0].TieBreaker
+	}
+	if err := a.checkTieBreakers(); err != nil {
+		return err
+	}
+	if err := a.evaluateBest(g); err != nil {
+		return err
+	}
+	a.recordBest()
+	a.BeginSearch(g, a.BestFitness)
+
+	loop := Loop{
+		Unit:        "generation",
+		Termination: &a.Termination,
+		Observers:   a.observers,
+		Epsilon:     a.Epsilon,
+		Step: func() (bool, error) {
+			return a.step(g)
+		},
+		Stats: a.stats,
+		Best: func() *Gene {
+			return a.BestGene
+		},
+		Report:           a.reportEpoch,
+		Checkpoint:       a,
+		CheckpointFreq:   a.CheckpointFreq,
+		CheckpointPath:   a.CheckpointPath,
+		CheckpointPolicy: a.CheckpointPolicy,
+	}
+	return loop.Run(ctx)
+}
+
+// step evolves every island for a generation, migrates between them when
+// it is due and returns true if the best fitness of any island improved
+// on a.Record
+func (a *Archipelago) step(g *graph.Graph) (bool, error) {
+	err := Parallel(len(a.Islands), len(a.Islands), func(i int) error {
+		if _, err := a.Islands[i].step(g); err != nil {
+			return fmt.Errorf("island %d: %w", i, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	a.Generations++
+	a.countEvaluations()
+
+	if a.MigrationInterval > 0 && a.Generations%a.MigrationInterval == 0 {
+		a.migrate()
+	}
+
+	a.recordBest()
+	return a.Improve(a.BestFitness), nil
+}
+
+// migrate copies the best genes of every island over the newest genes of
+// its neighbors. Migrants are collected from every island before any are
+// placed so the order islands are visited in doesn't matter. The elite of
+// the receiving island are never replaced
+//
+// This function is deterministic
+func (a *Archipelago) migrate() {
+	migrants := make([][]*Gene, len(a.Islands))
+	for i, island := range a.Islands {
+		migrants[i] = island.emigrants(a.Migrants)
+	}
+
+	for i, island := range a.Islands {
+		incoming := make([]*Gene, 0)
+		for _, source := range a.sources(i) {
+			incoming = append(incoming, migrants[source]...)
+		}
+
+		// migrants are copied since the same migrant can reach many islands
+		room := len(island.Genes) - min(island.Elitism, len(island.Genes))
+		incoming = incoming[:min(len(incoming), room)]
+		for j, gene := range incoming {
+			clone := *gene
+			island.Genes[len(island.Genes)-len(incoming)+j] = &clone
+		}
+	}
+}
+
+// sources returns the indices of the islands which send migrants to island `i`
+func (a *Archipelago) sources(i int) []int {
+	n := len(a.Islands)
+	if n < 2 {
+		return []int{}
+	}
+
+	switch a.Topology {
+	case TopologyFull:
+		sources := make([]int, 0, n-1)
+		for j := 0; j < n; j++ {
+			if j != i {
+				sources = append(sources, j)
+			}
+		}
+		return sources
+	case "", TopologyRing:
+		return []int{(i + n - 1) % n}
+	default:
+		panic("unknown migration topology: " + a.Topology)
+	}
+}
+
+// emigrants returns the best gene of the population followed by the next
+// fittest genes of the last generation, as they were evaluated before it
+// was bred. Before the first generation only the best gene emigrates
+func (p *GA) emigrants(n int) []*Gene {
+	if n < 1 || len(p.Genes) == 0 {
+		return []*Gene{}
+	}
+	emigrants := []*Gene{p.BestGene}
+	if len(p.fittest) > 1 {
+		emigrants = append(emigrants, p.fittest[1:min(n, len(p.fittest))]...)
+	}
+	return emigrants
+}
+
+// checkTieBreakers returns an error if an island doesn't break ties with the
+// tie-breaker of the archipelago
+func (a *Archipelago) checkTieBreakers() error {
+	for i, island := range a.Islands {
+		if tieBreaker(island.TieBreaker) != tieBreaker(a.TieBreaker) {
+			return fmt.Errorf("island %d breaks ties by %s, not %s like the archipelago", i, tieBreaker(island.TieBreaker), tieBreaker(a.TieBreaker))
+		}
+	}
+	return nil
+}
+
+// evaluateBest evaluates the best genes of the archipelago and its islands
+// again with the tie-breaker, which may have been set after the islands
+// were created
+func (a *Archipelago) evaluateBest(g *graph.Graph) error {
+	genes := []*Gene{a.BestGene}
+	for _, island := range a.Islands {
+		genes = append(genes, island.BestGene)
+	}
+	for _, gene := range genes {
+		if gene == nil || gene.Sequence == nil {
+			continue
+		}
+		fitness, secondary, err := Evaluate(g, gene.Sequence, a.TieBreaker)
+		if err != nil {
+			return err
+		}
+		gene.Fitness, gene.Secondary = fitness, secondary
+	}
+	for _, island := range a.Islands {
+		if island.BestGene != nil {
+			island.BestFitness = island.BestGene.Fitness
+		}
+	}
+	return nil
+}
+
+// recordBest updates the best gene of the archipelago from its islands.
+// Islands with the same best fitness are compared by the tie-breaker they
+// share
+func (a *Archipelago) recordBest() {
+	for _, island := range a.Islands {
+		if a.BestGene == nil || island.less(island.BestGene, a.BestGene) {
+			best := *island.BestGene
+			a.BestGene = &best
+			a.BestFitness = best.Fitness
+		}
+	}
+}
+
+// countEvaluations totals the fitness evaluations made by every island
+func (a *Archipelago) countEvaluations() {
+	a.Evaluations = 0
+	for _, island := range a.Islands {
+		a.Evaluations += island.Evaluations
+	}
+}
+
+func (a *Archipelago) reportEpoch() {
+	islandBest := make([]int, len(a.Islands))
+	for i, island := range a.Islands {
+		islandBest[i] = island.BestFitness
+	}
+	log.Printf("Epoch %d: Best fitness: %d Island fitness: %v\n", a.Generations, a.BestFitness, islandBest)
+}
+
+// GetBest will return the best fitness and sequence found on any island
+func (a *Archipelago) GetBest(g *graph.Graph) (int, *sequence.Sequence) {
+	var best *Gene
+	for _, island := range a.Islands {
+		if fit, _ := island.GetBest(g); fit != 0 && (best == nil || island.less(island.BestGene, best)) {
+			best = island.BestGene
+		}
+	}
+	if best == nil {
+		return 0, a.BestGene.Sequence
+	}
+	return best.Fitness, best.Sequence
+}
+
+// SynchronizeRNG will reseed the random number generator of every island,
+// see GA.SynchronizeRNG
+func (a *Archipelago) SynchronizeRNG() {
+	for _, island := range a.Islands {
+		island.SynchronizeRNG()
+	}
+}
+
+// tieBreaker returns the name of the tie-breaker `name`, which is
+// TieBreakerNone when it is empty
+func tieBreaker(name string) string {
+	if name == "" {
+		return TieBreakerNone
+	}
+	return name
+}
@@ -0,0 +1,149 @@
+package genetics
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/andey-robins/magical/checkpoint"
+	"github.com/andey-robins/magical/graph"
+	"github.com/andey-robins/magical/parsers/blif"
+)
+
+func newTestArchipelago(g *graph.Graph, topology string) *Archipelago {
+	seeds := IslandSeeds(7, 3)
+	islands := make([]*GA, len(seeds))
+	for i, seed := range seeds {
+		islands[i] = NewGA(10, 2, 0.5, g, seed, 0, "")
+		islands[i].Elitism = 1
+	}
+	return NewArchipelago(islands, topology, 2, 2, 7, 4, 0, "")
+}
+
+func TestArchipelagoIsDeterministic(t *testing.T) {
+	g := blif.LoadBlifAsGraph("../input/circuits/cm150a_128.blif")
+
+	for _, topology := range Topologies() {
+		one := newTestArchipelago(g, topology)
+		two := newTestArchipelago(g, topology)
+
+		one.Evolve(context.Background(), g)
+		two.Evolve(context.Background(), g)
+
+		if one.Generations != two.Generations || one.BestFitness != two.BestFitness {
+			t.Fatalf("%s: expected identical runs, got %d generations with fitness %d and %d generations with fitness %d",
+				topology, one.Generations, one.BestFitness, two.Generations, two.BestFitness)
+		}
+
+		for i := range one.Islands {
+			for j := range one.Islands[i].Genes {
+				if one.Islands[i].Genes[j].Sequence.ToString() != two.Islands[i].Genes[j].Sequence.ToString() {
+					t.Errorf("%s: expected island %d gene %d to be the same", topology, i, j)
+				}
+			}
+		}
+	}
+}
+
+func TestMigration(t *testing.T) {
+	g := blif.LoadBlifAsGraph("../input/circuits/cm150a_128.blif")
+	a := newTestArchipelago(g, TopologyRing)
+	a.Migrants = 1
+
+	best := a.Islands[0].BestGene.Sequence.ToString()
+	a.migrate()
+
+	last := a.Islands[1].Genes[len(a.Islands[1].Genes)-1]
+	if last.Sequence.ToString() != best {
+		t.Errorf("Expected the best gene of island 0 to migrate to island 1")
+	}
+}
+
+func TestArchipelagoCheckpoint(t *testing.T) {
+	cname := os.TempDir() + "/archipelago.json"
+	g := blif.LoadBlifAsGraph("../input/circuits/cm150a_128.blif")
+
+	a := newTestArchipelago(g, TopologyRing)
+	a.Evolve(context.Background(), g)
+	checkpoint.Save(cname, a)
+
+	loaded := &Archipelago{}
+	checkpoint.Load(cname, loaded)
+
+	if len(loaded.Islands) != len(a.Islands) || loaded.BestFitness != a.BestFitness {
+		t.Fatalf("Expected the archipelago to be restored from its checkpoint")
+	}
+
+	a.SynchronizeRNG()
+	loaded.SynchronizeRNG()
+	a.Evolve(context.Background(), g)
+	loaded.Evolve(context.Background(), g)
+
+	for i := range a.Islands {
+		for j := range a.Islands[i].Genes {
+			if a.Islands[i].Genes[j].Sequence.ToString() != loaded.Islands[i].Genes[j].Sequence.ToString() {
+				t.Errorf("Expected island %d gene %d to be the same after resuming", i, j)
+			}
+		}
+	}
+}
+
+func TestMigrantsAreEvaluated(t *testing.T) {
+	g := blif.LoadBlifAsGraph("../input/circuits/cm150a_128.blif")
+	a := newTestArchipelago(g, TopologyRing)
+	a.Migrants = 3
+	a.MigrationInterval = 1
+	a.MaxGenerations = 1
+	for _, island := range a.Islands {
+		island.Selection = SelectionTournament
+		island.Elitism = 0
+	}
+	if err := a.Evolve(context.Background(), g); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, island := range a.Islands {
+		for _, gene := range island.Genes[len(island.Genes)-a.Migrants:] {
+			fitness, _, err := Evaluate(g, gene.Sequence, island.TieBreaker)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gene.Fitness == 0 || gene.Fitness != fitness {
+				t.Errorf("expected island %d to receive evaluated migrants, got fitness %d for a sequence of %d", i, gene.Fitness, fitness)
+			}
+		}
+	}
+}
+
+func TestArchipelagoBestBreaksTies(t *testing.T) {
+	g := blif.LoadBlifAsGraph("../input/circuits/cm150a_128.blif")
+	a := newTestArchipelago(g, TopologyRing)
+	for i, island := range a.Islands {
+		island.TieBreaker = TieBreakerArea
+		island.BestGene = &Gene{Sequence: island.BestGene.Sequence, Fitness: 20, Secondary: 10 - i}
+		island.BestFitness = 20
+	}
+
+	a.BestGene = nil
+	a.recordBest()
+	if a.BestGene.Secondary != 10-len(a.Islands)+1 {
+		t.Errorf("expected the island with the best secondary fitness to be the best, got %d", a.BestGene.Secondary)
+	}
+}
+
+func TestArchipelagoSharesTieBreaker(t *testing.T) {
+	g := blif.LoadBlifAsGraph("../input/circuits/cm150a_128.blif")
+	a := newTestArchipelago(g, TopologyRing)
+	a.MaxGenerations = 1
+	a.Islands[1].TieBreaker = TieBreakerArea
+	if err := a.Evolve(context.Background(), g); err == nil {
+		t.Error("expected islands which break ties differently to be refused")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected an archipelago of islands which break ties differently to panic")
+		}
+	}()
+	NewArchipelago(a.Islands, TopologyRing, 2, 2, 7, 4, 0, "")
+}
@@ -0,0 +1,139 @@
+package genetics
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/andey-robins/magical/graph"
+	"github.com/andey-robins/magical/sequence"
+)
+
+// Local search strategies hill-climb the best genes of each generation
+// through graph.Neighborhood. Steepest descent takes the best move of the
+// whole neighborhood while first improvement takes the first move it finds
+// which improves the gene.
+const (
+	LocalSearchNone     = "none"
+	LocalSearchSteepest = "steepest"
+	LocalSearchFirst    = "first"
+)
+
+// LocalSearchNames returns the names of every available local search
+func LocalSearchNames() []string {
+	return []string{LocalSearchNone, LocalSearchSteepest, LocalSearchFirst}
+}
+
+// localSearch improves the LocalSearchGenes best genes of the sorted
+// population in place with the population's local search strategy, and
+// updates the best gene if one of them improved on it. It runs after
+// execute so the improved genes go on to be parents
+//
+// This function is deterministic. First improvement starts each scan at a
+// position drawn from p.RNG, and steepest descent evaluates its neighborhood
+// over p.Workers but always keeps the first of equally good moves
+func (p *GA) localSearch(g *graph.Graph) error {
+	if p.LocalSearch == "" || p.LocalSearch == LocalSearchNone {
+		return nil
+	}
+
+	n := min(max(p.LocalSearchGenes, 1), len(p.parents))
+	for _, gene := range p.parents[:n] {
+		var err error
+		switch p.LocalSearch {
+		case LocalSearchSteepest:
+			err = p.climb(g, gene, p.steepestMove)
+		case LocalSearchFirst:
+			err = p.climb(g, gene, p.firstMove)
+		default:
+			panic(fmt.Sprintf("unknown local search: %s", p.LocalSearch))
+		}
+		if err != nil {
+			return fmt.Errorf("local search: %w", err)
+		}
+	}
+
+	// improved genes may now be better than the genes ahead of them
+	sort.SliceStable(p.parents[:n], func(i, j int) bool {
+		return p.less(p.parents[i], p.parents[j])
+	})
+	copy(p.Genes, p.parents)
+
+	if p.less(p.parents[0], p.BestGene) {
+		best := *p.parents[0]
+		p.BestFitness = best.Fitness
+		p.BestGene = &best
+	}
+	return nil
+}
+
+// climb repeatedly applies the improving move found by `next` to `gene`
+// until there is none left or LocalSearchSteps moves have been made
+func (p *GA) climb(g *graph.Graph, gene *Gene, next func(*graph.Graph, *Gene) (*Gene, error)) error {
+	for steps := 0; p.LocalSearchSteps <= 0 || steps < p.LocalSearchSteps; steps++ {
+		improved, err := next(g, gene)
+		if err != nil || improved == nil {
+			return err
+		}
+		*gene = *improved
+	}
+	return nil
+}
+
+// steepestMove returns the best neighbor of `gene` if it is better than
+// `gene`, and nil otherwise
+func (p *GA) steepestMove(g *graph.Graph, gene *Gene) (*Gene, error) {
+	seq := gene.Sequence.GetSequence()
+	moves := g.Neighborhood(seq)
+	neighbors := make([]*Gene, len(moves))
+
+	err := Parallel(p.Workers, len(moves), func(i int) error {
+		var err error
+		neighbors[i], err = p.neighbor(g, moves[i].Apply(seq))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	p.Evaluations += len(moves)
+
+	var best *Gene
+	for _, neighbor := range neighbors {
+		if p.less(neighbor, gene) && (best == nil || p.less(neighbor, best)) {
+			best = neighbor
+		}
+	}
+	return best, nil
+}
+
+// firstMove returns the first neighbor of `gene` which is better than
+// `gene`, and nil if there is none
+func (p *GA) firstMove(g *graph.Graph, gene *Gene) (*Gene, error) {
+	seq := gene.Sequence.GetSequence()
+	moves := g.Neighborhood(seq)
+	if len(moves) == 0 {
+		return nil, nil
+	}
+
+	start := p.RNG.IntN(len(moves))
+	for i := range moves {
+		neighbor, err := p.neighbor(g, moves[(start+i)%len(moves)].Apply(seq))
+		if err != nil {
+			return nil, err
+		}
+		p.Evaluations++
+		if p.less(neighbor, gene) {
+			return neighbor, nil
+		}
+	}
+	return nil, nil
+}
+
+// neighbor creates and evaluates the gene for a neighboring sequence
+func (p *GA) neighbor(g *graph.Graph, seq []int) (*Gene, error) {
+	s := sequence.NewSequence(seq)
+	fitness, secondary, err := p.evaluate(g, s)
+	if err != nil {
+		return nil, err
+	}
+	return &Gene{s, fitness, secondary}, nil
+}
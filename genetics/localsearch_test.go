@@ -0,0 +1,39 @@
+package genetics
+
+import (
+	"testing"
+
+	"github.com/andey-robins/magical/parsers/blif"
+)
+
+func TestLocalSearchReachesLocalOptimum(t *testing.T) {
+	g := blif.LoadBlifAsGraph("../input/circuits/cm150a_128.blif")
+
+	for _, strategy := range []string{LocalSearchSteepest, LocalSearchFirst} {
+		pop := NewGA(20, 1, 0.2, g, 1, 0, "")
+		pop.LocalSearch = strategy
+		pop.LocalSearchGenes = 2
+		pop.SynchronizeRNG()
+
+		pop.evaluation(g)
+		pop.execute()
+		before := *pop.BestGene
+		if err := pop.localSearch(g); err != nil {
+			t.Fatalf("%s: %v", strategy, err)
+		}
+
+		if pop.less(&before, pop.BestGene) {
+			t.Errorf("%s: local search made the best gene worse", strategy)
+		}
+
+		// no neighbor of a local optimum is an improvement
+		for _, gene := range pop.parents[:2] {
+			if !g.IsValidSequence(gene.Sequence) {
+				t.Errorf("%s: local search produced an invalid sequence", strategy)
+			}
+			if improved, _ := pop.steepestMove(g, gene); improved != nil {
+				t.Errorf("%s: expected gene to be a local optimum", strategy)
+			}
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package genetics
+
+import (
+	"sort"
+
+	"github.com/andey-robins/magical/graph"
+	"github.com/andey-robins/magical/sequence"
+)
+
+// A MutationOperator returns a mutated copy of a sequence that is still
+// valid for the graph. The seed makes each mutation reproducible
+type MutationOperator func(g *graph.Graph, s *sequence.Sequence, seed int) *sequence.Sequence
+
+// mutationOperators maps the names used in configs and on the command
+// line to their operators
+var mutationOperators = map[string]MutationOperator{
+	"swap":    (*graph.Graph).SmartMutate,
+	"insert":  (*graph.Graph).InsertMutate,
+	"block":   (*graph.Graph).BlockMutate,
+	"reverse": (*graph.Graph).ReverseMutate,
+}
+
+// MutationNames returns the names of every available mutation operator
+func MutationNames() []string {
+	names := make([]string, 0, len(mutationOperators))
+	for name := range mutationOperators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetMutation returns the mutation operator with the given name
+func GetMutation(name string) (MutationOperator, bool) {
+	op, ok := mutationOperators[name]
+	return op, ok
+}
+
+// pickMutation chooses one of the population's mutation operators with a
+// chance proportional to its weight. Without any weights every mutation
+// is the peer swap that SAGA has always used, and no random number is drawn
+// when there is only one operator to choose from
+func (p *GA) pickMutation() MutationOperator {
+	names := make([]string, 0, len(p.MutationOperators))
+	total := 0.0
+	for name, weight := range p.MutationOperators {
+		if weight > 0 {
+			names = append(names, name)
+			total += weight
+		}
+	}
+
+	if len(names) == 0 {
+		return mutationOperators["swap"]
+	}
+
+	// sort so that the choice doesn't depend on map iteration order
+	sort.Strings(names)
+	name := names[0]
+	if len(names) > 1 {
+		r := p.RNG.Float64() * total
+		for _, name = range names {
+			r -= p.MutationOperators[name]
+			if r < 0 {
+				break
+			}
+		}
+	}
+
+	op, ok := GetMutation(name)
+	if !ok {
+		panic("unknown mutation operator: " + name)
+	}
+	return op
+}
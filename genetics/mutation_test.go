@@ -0,0 +1,49 @@
+package genetics
+
+import (
+	"testing"
+
+	"github.com/andey-robins/magical/parsers/blif"
+)
+
+func TestMutationChanceIsHonored(t *testing.T) {
+	g := blif.LoadBlifAsGraph("../input/circuits/cm150a_128.blif")
+
+	tests := []struct {
+		chance    float64
+		operators map[string]float64
+		changed   bool
+	}{
+		{0.0, nil, false},
+		{1.0, nil, true},
+		{1.0, map[string]float64{"insert": 1, "block": 1, "reverse": 1}, true},
+	}
+
+	for _, test := range tests {
+		pop := NewGA(20, 1, test.chance, g, 1, 0, "")
+		pop.MutationOperators = test.operators
+
+		before := make([]string, len(pop.Genes))
+		for i, gene := range pop.Genes {
+			before[i] = gene.Sequence.ToString()
+		}
+
+		pop.SynchronizeRNG()
+		pop.mutate(g)
+
+		changed := false
+		for i, gene := range pop.Genes {
+			if !g.IsValidSequence(gene.Sequence) {
+				t.Errorf("Mutation produced an invalid sequence")
+			}
+			if gene.Sequence.ToString() != before[i] {
+				changed = true
+			}
+		}
+
+		if changed != test.changed {
+			t.Errorf("Expected changes with mutation chance %v and operators %v to be %t, got %t",
+				test.chance, test.operators, test.changed, changed)
+		}
+	}
+}
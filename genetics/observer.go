@@ -0,0 +1,136 @@
+package genetics
+
+import (
+	"time"
+)
+
+// Stats describe the progress of a search when its observers are notified
+// of the last generation. The average, median and worst fitness are the
+// current fitness for searches without a population
+type Stats struct {
+	Generation    int
+	BestFitness   int
+	AvgFitness    float64
+	MedianFitness float64
+	WorstFitness  int
+	Diversity     Diversity // only Unique is set, to 1, for searches without a population
+	Evaluations   int       // the sequences simulated, which doesn't include CacheHits
+	CacheHits     int
+	CacheMisses   int
+	Elapsed       time.Duration // since Evolve was called
+	Seed          int
+	StopReason    string // only set when the search has finished
+}
+
+// An Observer is notified of the progress of Evolve on the goroutine
+// running it. OnImprovement is called when a generation improves the best
+// fitness, before OnGeneration and then OnCheckpoint if a checkpoint is
+// saved. OnFinish is called once when the search stops. An observer
+// which wants to stop the search early should cancel the context passed to
+// Evolve.
+type Observer interface {
+	OnGeneration(stats Stats)
+	OnImprovement(stats Stats, best *Gene)
+	OnCheckpoint(stats Stats, path string)
+	OnFinish(stats Stats)
+}
+
+// BaseObserver does nothing when notified. Embed it in an observer which
+// only needs some of the methods of Observer
+type BaseObserver struct{}
+
+func (BaseObserver) OnGeneration(stats Stats)              {}
+func (BaseObserver) OnImprovement(stats Stats, best *Gene) {}
+func (BaseObserver) OnCheckpoint(stats Stats, path string) {}
+func (BaseObserver) OnFinish(stats Stats)                  {}
+
+// Observers notifies each of a list of observers in the order they were added
+type Observers []Observer
+
+func (obs Observers) Generation(stats Stats) {
+	for _, o := range obs {
+		o.OnGeneration(stats)
+	}
+}
+
+func (obs Observers) Improvement(stats Stats, best *Gene) {
+	for _, o := range obs {
+		o.OnImprovement(stats, best)
+	}
+}
+
+func (obs Observers) Checkpoint(stats Stats, path string) {
+	for _, o := range obs {
+		o.OnCheckpoint(stats, path)
+	}
+}
+
+func (obs Observers) Finish(stats Stats) {
+	for _, o := range obs {
+		o.OnFinish(stats)
+	}
+}
+
+// AddObserver adds an observer which is notified of the progress of Evolve
+func (p *GA) AddObserver(o Observer) {
+	p.observers = append(p.observers, o)
+}
+
+// AddObserver adds an observer which is notified of the progress of Evolve.
+// The observers of the islands aren't notified
+func (a *Archipelago) AddObserver(o Observer) {
+	a.observers = append(a.observers, o)
+}
+
+func (p *GA) stats() Stats {
+	s := Stats{
+		Generation:    p.Generations,
+		BestFitness:   p.BestFitness,
+		AvgFitness:    p.AvgFitness,
+		MedianFitness: p.MedianFitness,
+		WorstFitness:  p.WorstFitness,
+		Diversity:     p.Diversity,
+		Evaluations:   p.Evaluations,
+		Elapsed:       p.Elapsed(),
+		Seed:          p.Seed,
+		StopReason:    p.StopReason,
+	}
+	s.CacheHits, s.CacheMisses = p.cacheStats()
+	return s
+}
+
+// stats of an archipelago average the average and median fitness and the
+// diversity of its islands, weighted by their size. The worst fitness is the
+// worst of any island, and the unique genes and cache lookups are totalled
+// over the islands
+func (a *Archipelago) stats() Stats {
+	s := Stats{
+		Generation:  a.Generations,
+		BestFitness: a.BestFitness,
+		Evaluations: a.Evaluations,
+		Elapsed:     a.Elapsed(),
+		Seed:        a.Seed,
+		StopReason:  a.StopReason,
+	}
+
+	genes := 0
+	for _, island := range a.Islands {
+		s.AvgFitness += island.AvgFitness * float64(len(island.Genes))
+		s.MedianFitness += island.MedianFitness * float64(len(island.Genes))
+		s.WorstFitness = max(s.WorstFitness, island.WorstFitness)
+		hits, misses := island.cacheStats()
+		s.CacheHits += hits
+		s.CacheMisses += misses
+		s.Diversity.Unique += island.Diversity.Unique
+		s.Diversity.Distance += island.Diversity.Distance * float64(len(island.Genes))
+		s.Diversity.Entropy += island.Diversity.Entropy * float64(len(island.Genes))
+		genes += len(island.Genes)
+	}
+	if genes > 0 {
+		s.AvgFitness /= float64(genes)
+		s.MedianFitness /= float64(genes)
+		s.Diversity.Distance /= float64(genes)
+		s.Diversity.Entropy /= float64(genes)
+	}
+	return s
+}
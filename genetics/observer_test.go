@@ -0,0 +1,109 @@
+package genetics
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/andey-robins/magical/checkpoint"
+	"github.com/andey-robins/magical/parsers/blif"
+)
+
+// recorder counts its notifications and cancels the search after `stopAt`
+// generations
+type recorder struct {
+	BaseObserver
+	stopAt       int
+	cancel       context.CancelFunc
+	generations  []Stats
+	improvements int
+	checkpoints  []string
+	finished     []Stats
+}
+
+func (r *recorder) OnGeneration(stats Stats) {
+	r.generations = append(r.generations, stats)
+	if stats.Generation >= r.stopAt {
+		r.cancel()
+	}
+}
+
+func (r *recorder) OnImprovement(stats Stats, best *Gene) {
+	r.improvements++
+}
+
+func (r *recorder) OnCheckpoint(stats Stats, path string) {
+	r.checkpoints = append(r.checkpoints, path)
+}
+
+func (r *recorder) OnFinish(stats Stats) {
+	r.finished = append(r.finished, stats)
+}
+
+func TestObserverNotifications(t *testing.T) {
+	g := blif.LoadBlifAsGraph("../input/circuits/cm150a_128.blif")
+	dir := t.TempDir()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r := &recorder{stopAt: 5, cancel: cancel}
+
+	pop := NewGA(20, 1_000, 0.2, g, 1, 2, dir)
+	pop.AddObserver(r)
+	pop.AddObserver(BaseObserver{})
+	pop.Evolve(ctx, g)
+
+	if len(r.generations) != 5 {
+		t.Fatalf("expected 5 generations, got %d", len(r.generations))
+	}
+	for i, stats := range r.generations {
+		if stats.Generation != i+1 || stats.Evaluations != (i+1)*20 {
+			t.Errorf("unexpected stats for generation %d: %+v", i+1, stats)
+		}
+		if stats.Diversity.Unique < 1 || stats.Diversity.Unique > 20 {
+			t.Errorf("expected between 1 and 20 unique genes, got %d", stats.Diversity.Unique)
+		}
+	}
+
+	// the initial checkpoint, generations 2 and 4, and the last generation
+	expected := []string{dir + "/0.json", dir + "/2.json", dir + "/4.json", dir + "/5.json"}
+	if len(r.checkpoints) != len(expected) {
+		t.Fatalf("expected checkpoints %v, got %v", expected, r.checkpoints)
+	}
+	for i, path := range expected {
+		if r.checkpoints[i] != path {
+			t.Errorf("expected checkpoint %s, got %s", path, r.checkpoints[i])
+		}
+	}
+
+	if len(r.finished) != 1 || r.finished[0].StopReason != StopInterrupted {
+		t.Errorf("expected to finish once when interrupted, got %+v", r.finished)
+	}
+	if r.improvements > 5 {
+		t.Errorf("expected at most one improvement per generation, got %d", r.improvements)
+	}
+}
+
+func TestLastCheckpointRecordsStop(t *testing.T) {
+	g := blif.LoadBlifAsGraph("../input/circuits/cm150a_128.blif")
+	dir := t.TempDir()
+	r := &recorder{stopAt: math.MaxInt}
+
+	pop := NewGA(20, 1_000, 0.2, g, 1, 2, dir)
+	pop.MaxGenerations = 4
+	pop.AddObserver(r)
+	pop.Evolve(context.Background(), g)
+
+	// the last generation is due a checkpoint so it isn't saved again
+	expected := []string{dir + "/0.json", dir + "/2.json", dir + "/4.json"}
+	if !reflect.DeepEqual(r.checkpoints, expected) {
+		t.Fatalf("expected checkpoints %v, got %v", expected, r.checkpoints)
+	}
+
+	loaded := &GA{}
+	checkpoint.Load(dir+"/4.json", loaded)
+	if loaded.StopReason != StopGenerations {
+		t.Errorf("expected the last checkpoint to record the stop reason, got %q", loaded.StopReason)
+	}
+}
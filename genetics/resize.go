@@ -0,0 +1,44 @@
+package genetics
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/andey-robins/magical/graph"
+)
+
+// Resize changes the size of the population to `n` genes, for example to
+// intensify a resumed search with a smaller population. A larger
+// population is filled with fresh random genes. A smaller one keeps its `n`
+// fittest genes, so the population is evaluated first since the genes
+// bred in the last generation haven't been yet. The elite, tournaments and
+// local search must fit in the new population
+//
+// This function uses random numbers, but pulls from p.RNG which is seeded
+// deterministically. Like Evolve it seeds p.RNG for checkpoints from before
+// the generator was saved
+func (p *GA) Resize(g *graph.Graph, n int) error {
+	if n <= p.Elitism || n < p.TournamentSize || n < p.LocalSearchGenes {
+		return fmt.Errorf("a population of %d genes is too small for %d elite, tournaments of %d or local search of %d genes",
+			n, p.Elitism, p.TournamentSize, p.LocalSearchGenes)
+	}
+
+	if n < len(p.Genes) {
+		if err := p.evaluation(g); err != nil {
+			return err
+		}
+		sort.SliceStable(p.Genes, func(i, j int) bool {
+			return p.less(p.Genes[i], p.Genes[j])
+		})
+		p.Genes = p.Genes[:n]
+	}
+	if p.RNG == nil {
+		p.SynchronizeRNG()
+	}
+	for len(p.Genes) < n {
+		p.Genes = append(p.Genes, &Gene{Sequence: g.SynthesizeRandomValidSequence(p.RNG.Int())})
+	}
+
+	p.Size = n
+	return nil
+}
@@ -0,0 +1,68 @@
+package genetics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/andey-robins/magical/parsers/blif"
+)
+
+func TestResize(t *testing.T) {
+	g := blif.LoadBlifAsGraph("../input/circuits/cm150a_128.blif")
+	pop := NewGA(10, 5, 0.2, g, 1, 0, "")
+	pop.MaxGenerations = 3
+	if err := pop.Evolve(context.Background(), g); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := pop.Resize(g, 16); err != nil {
+		t.Fatal(err)
+	}
+	if pop.Size != 16 || len(pop.Genes) != 16 {
+		t.Fatalf("expected 16 genes, got %d of size %d", len(pop.Genes), pop.Size)
+	}
+	for _, gene := range pop.Genes[10:] {
+		if !g.IsValidSequence(gene.Sequence) {
+			t.Errorf("expected the new genes to be valid sequences")
+		}
+	}
+
+	if err := pop.Resize(g, 4); err != nil {
+		t.Fatal(err)
+	}
+	if pop.Size != 4 || len(pop.Genes) != 4 {
+		t.Fatalf("expected 4 genes, got %d of size %d", len(pop.Genes), pop.Size)
+	}
+	for i, gene := range pop.Genes {
+		if gene.Fitness == 0 || (i > 0 && gene.Fitness < pop.Genes[i-1].Fitness) {
+			t.Errorf("expected the fittest genes to be kept in order, got %d at %d", gene.Fitness, i)
+		}
+	}
+
+	pop.MaxGenerations = 6
+	if err := pop.Evolve(context.Background(), g); err != nil {
+		t.Fatal(err)
+	}
+	if len(pop.Genes) != 4 {
+		t.Errorf("expected the population to stay at 4 genes, got %d", len(pop.Genes))
+	}
+
+	pop.Elitism = 4
+	if err := pop.Resize(g, 4); err == nil {
+		t.Errorf("expected a population no larger than the elite to be refused")
+	}
+}
+
+func TestResizeWithoutRNG(t *testing.T) {
+	g := blif.LoadBlifAsGraph("../input/circuits/cm150a_128.blif")
+	pop := NewGA(4, 5, 0.2, g, 1, 0, "")
+	// checkpoints from before the generator was saved load without it
+	pop.RNG = nil
+
+	if err := pop.Resize(g, 8); err != nil {
+		t.Fatal(err)
+	}
+	if len(pop.Genes) != 8 || pop.RNG == nil {
+		t.Errorf("expected 8 genes from a seeded generator, got %d", len(pop.Genes))
+	}
+}
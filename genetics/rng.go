@@ -0,0 +1,47 @@
+package genetics
+
+import (
+	"encoding/json"
+	"math/rand/v2"
+)
+
+// pcgStream selects the stream of the PCG generator; searches are told
+// apart by their seed alone
+const pcgStream = 0x5a6a
+
+// An RNG is the random number generator of a search. It is a PCG generator
+// whose state is saved in checkpoints as a base64 string, so that a resumed
+// search draws the same numbers the search would have drawn had it never
+// stopped. It must only be used from the goroutine running the search
+type RNG struct {
+	*rand.Rand
+	src *rand.PCG
+}
+
+// NewRNG creates a generator seeded with `seed`
+func NewRNG(seed int) *RNG {
+	src := rand.NewPCG(uint64(seed), pcgStream)
+	return &RNG{rand.New(src), src}
+}
+
+func (r *RNG) MarshalJSON() ([]byte, error) {
+	state, err := r.src.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(state)
+}
+
+func (r *RNG) UnmarshalJSON(data []byte) error {
+	var state []byte
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+
+	src := &rand.PCG{}
+	if err := src.UnmarshalBinary(state); err != nil {
+		return err
+	}
+	r.Rand, r.src = rand.New(src), src
+	return nil
+}
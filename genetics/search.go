@@ -0,0 +1,90 @@
+package genetics
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/andey-robins/magical/checkpoint"
+)
+
+// A Loop is the search loop which the Evolve of every optimizer runs. It
+// steps the search one generation at a time until Termination stops it,
+// notifying Observers and checkpointing it along the way. Unit names a
+// generation in the log, such as "generation" or "iteration"
+type Loop struct {
+	Unit        string
+	Termination *Termination
+	Observers   Observers
+	Epsilon     int
+
+	// Step runs one generation and returns true if it improved the best
+	// fitness. Stats describes the search, Best returns its best gene and
+	// Report logs the epoch report of the last generation
+	Step   func() (bool, error)
+	Stats  func() Stats
+	Best   func() *Gene
+	Report func()
+
+	// Checkpoint is saved every CheckpointFreq generations, or never when
+	// it is 0, as the checkpoint of the generation in CheckpointPath
+	Checkpoint       interface{}
+	CheckpointFreq   int
+	CheckpointPath   string
+	CheckpointPolicy checkpoint.Policy
+}
+
+// Run steps the search until it stops. If a generation fails the search
+// stops without checkpointing it and the error is returned
+func (l *Loop) Run(ctx context.Context) error {
+	t := l.Termination
+	if l.CheckpointFreq > 0 {
+		l.save()
+	}
+
+	for stopped := l.shouldStop(ctx); !stopped; {
+		improved, err := l.Step()
+		if err != nil {
+			generation := l.Stats().Generation + 1
+			t.StopReason = StopError
+			log.Printf("Stopped in %s %d: %v\n", l.Unit, generation, err)
+			l.Observers.Finish(l.Stats())
+			return fmt.Errorf("%s %d: %w", l.Unit, generation, err)
+		}
+		if improved {
+			l.Observers.Improvement(l.Stats(), l.Best())
+		}
+
+		l.Report()
+		l.Observers.Generation(l.Stats())
+
+		stopped = l.shouldStop(ctx)
+		if l.due() {
+			l.save()
+		}
+	}
+
+	if l.CheckpointFreq > 0 && !l.due() {
+		l.save()
+	}
+	log.Printf("Stopped after %d %ss: %s\n", l.Stats().Generation, l.Unit, t.StopReason)
+	l.Observers.Finish(l.Stats())
+	return nil
+}
+
+// shouldStop checks the rules of the search's Termination
+func (l *Loop) shouldStop(ctx context.Context) bool {
+	s := l.Stats()
+	return l.Termination.ShouldStop(ctx, s.Generation, s.BestFitness, l.Termination.Stagnant, l.Epsilon)
+}
+
+// due returns true if the last generation is checkpointed by CheckpointFreq
+func (l *Loop) due() bool {
+	return l.CheckpointFreq > 0 && l.Stats().Generation%l.CheckpointFreq == 0
+}
+
+// save checkpoints the last generation and notifies the observers
+func (l *Loop) save() {
+	path := checkpoint.Write(l.CheckpointPath, l.Stats().Generation, l.CheckpointPolicy, l.Checkpoint)
+	l.Observers.Checkpoint(l.Stats(), path)
+}
@@ -0,0 +1,48 @@
+package genetics
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestLoopStopsOnError(t *testing.T) {
+	dir := t.TempDir()
+	var term Termination
+	generations := 0
+	r := &recorder{stopAt: 100}
+
+	loop := Loop{
+		Unit:        "iteration",
+		Termination: &term,
+		Observers:   Observers{r},
+		Epsilon:     10,
+		Step: func() (bool, error) {
+			if generations == 2 {
+				return false, errors.New("broken")
+			}
+			generations++
+			return true, nil
+		},
+		Stats: func() Stats {
+			return Stats{Generation: generations, BestFitness: 10, StopReason: term.StopReason}
+		},
+		Best:           func() *Gene { return &Gene{} },
+		Report:         func() {},
+		Checkpoint:     map[string]int{},
+		CheckpointFreq: 1,
+		CheckpointPath: dir,
+	}
+
+	err := loop.Run(context.Background())
+	if err == nil || err.Error() != "iteration 3: broken" {
+		t.Fatalf("expected the error of iteration 3, got %v", err)
+	}
+	if term.StopReason != StopError || len(r.generations) != 2 || r.improvements != 2 {
+		t.Errorf("expected 2 iterations before stopping with an error, got %d stopped by %q", len(r.generations), term.StopReason)
+	}
+	// the initial checkpoint and the 2 iterations, but not the failed one
+	if len(r.checkpoints) != 3 || len(r.finished) != 1 {
+		t.Errorf("expected 3 checkpoints and to finish once, got %v and %d", r.checkpoints, len(r.finished))
+	}
+}
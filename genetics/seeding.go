@@ -0,0 +1,38 @@
+package genetics
+
+import (
+	"github.com/andey-robins/magical/graph"
+	"github.com/andey-robins/magical/sequence"
+)
+
+// SeedSequences replaces the newest genes of the population with `seqs`,
+// for example the results of earlier runs or of graph heuristics. Each
+// sequence is repaired to be valid for `g` and evaluated straight away so
+// that a seed better than every random gene becomes the best gene. Only
+// as many seeds as fit in the population are used
+func (p *GA) SeedSequences(g *graph.Graph, seqs []*sequence.Sequence) {
+	n := min(len(seqs), len(p.Genes))
+	for i, seq := range seqs[:n] {
+		seq = g.RepairSequence(seq)
+		fitness, secondary, err := p.evaluate(g, seq)
+		if err != nil {
+			panic(err)
+		}
+
+		gene := &Gene{seq, fitness, secondary}
+		p.Genes[len(p.Genes)-n+i] = gene
+		if p.BestGene == nil || p.BestGene.Sequence == nil || p.less(gene, p.BestGene) {
+			best := *gene
+			p.BestFitness = best.Fitness
+			p.BestGene = &best
+		}
+	}
+}
+
+// SeedSequences seeds every island with `seqs`, see GA.SeedSequences
+func (a *Archipelago) SeedSequences(g *graph.Graph, seqs []*sequence.Sequence) {
+	for _, island := range a.Islands {
+		island.SeedSequences(g, seqs)
+	}
+	a.recordBest()
+}
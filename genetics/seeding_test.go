@@ -0,0 +1,33 @@
+package genetics
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/andey-robins/magical/parsers/blif"
+	"github.com/andey-robins/magical/sequence"
+)
+
+func TestSeedSequences(t *testing.T) {
+	g := blif.LoadBlifAsGraph("../input/circuits/cm150a_128.blif")
+	pop := NewGA(10, 1, 0.2, g, 1, 0, "")
+
+	greedy := g.GreedySequence()
+	reversed := greedy.GetSequence()
+	for i, j := 0, len(reversed)-1; i < j; i, j = i+1, j-1 {
+		reversed[i], reversed[j] = reversed[j], reversed[i]
+	}
+
+	pop.SeedSequences(g, []*sequence.Sequence{greedy, sequence.NewSequence(reversed)})
+
+	mem, _ := g.SimulateSequence(greedy)
+	if !reflect.DeepEqual(pop.Genes[8].Sequence.GetSequence(), greedy.GetSequence()) || pop.Genes[8].Fitness != mem.GetMaxUtilization() {
+		t.Errorf("expected the first seed to replace the second to last gene")
+	}
+	if !g.IsValidSequence(pop.Genes[9].Sequence) {
+		t.Errorf("expected an invalid seed to be repaired")
+	}
+	if pop.BestFitness > mem.GetMaxUtilization() {
+		t.Errorf("expected best fitness of at most %d, got %d", mem.GetMaxUtilization(), pop.BestFitness)
+	}
+}
@@ -0,0 +1,124 @@
+package genetics
+
+import (
+	"fmt"
+	"math/rand/v2"
+)
+
+// Selection strategies decide which genes become parents of the next
+// generation. Truncation keeps the best TruncationRatio of the population
+// and picks parents uniformly from them, the others pick parents from
+// the whole population and only carry the elite over unchanged.
+const (
+	SelectionTruncation = "truncation"
+	SelectionTournament = "tournament"
+	SelectionRank       = "rank"
+	SelectionRoulette   = "roulette"
+)
+
+// SelectionNames returns the names of every available selection strategy
+func SelectionNames() []string {
+	return []string{SelectionTruncation, SelectionTournament, SelectionRank, SelectionRoulette}
+}
+
+// A Selector picks a parent from a pool of genes which is sorted from
+// best to worst
+type Selector interface {
+	Select(pool []*Gene, rng *rand.Rand) *Gene
+}
+
+// uniformSelector picks any gene in the pool with equal chance
+type uniformSelector struct{}
+
+func (s uniformSelector) Select(pool []*Gene, rng *rand.Rand) *Gene {
+	return pool[rng.IntN(len(pool))]
+}
+
+// tournamentSelector draws `k` genes from the pool and picks the best of them
+type tournamentSelector struct {
+	k int
+}
+
+func (s tournamentSelector) Select(pool []*Gene, rng *rand.Rand) *Gene {
+	// the pool is sorted, so the lowest index drawn is the best gene
+	best := rng.IntN(len(pool))
+	for i := 1; i < s.k; i++ {
+		if idx := rng.IntN(len(pool)); idx < best {
+			best = idx
+		}
+	}
+	return pool[best]
+}
+
+// rankSelector picks genes with a chance proportional to their linear
+// rank, so the best of n genes is n times as likely as the worst
+type rankSelector struct{}
+
+func (s rankSelector) Select(pool []*Gene, rng *rand.Rand) *Gene {
+	n := len(pool)
+	r := rng.IntN(n * (n + 1) / 2)
+	for i := 0; i < n; i++ {
+		r -= n - i
+		if r < 0 {
+			return pool[i]
+		}
+	}
+	return pool[n-1]
+}
+
+// rouletteSelector picks genes with a chance proportional to how much
+// smaller their footprint is than the worst footprint in the pool
+type rouletteSelector struct{}
+
+func (s rouletteSelector) Select(pool []*Gene, rng *rand.Rand) *Gene {
+	worst := pool[len(pool)-1].Fitness
+	total := 0
+	for _, gene := range pool {
+		total += worst - gene.Fitness + 1
+	}
+
+	r := rng.IntN(total)
+	for _, gene := range pool {
+		r -= worst - gene.Fitness + 1
+		if r < 0 {
+			return gene
+		}
+	}
+	return pool[len(pool)-1]
+}
+
+// selector returns the parent selector for the population's strategy.
+// An empty strategy is truncation so that older checkpoints keep their
+// behavior
+func (p *GA) selector() Selector {
+	switch p.Selection {
+	case "", SelectionTruncation:
+		return uniformSelector{}
+	case SelectionTournament:
+		k := p.TournamentSize
+		if k < 1 {
+			k = 2
+		}
+		return tournamentSelector{k}
+	case SelectionRank:
+		return rankSelector{}
+	case SelectionRoulette:
+		return rouletteSelector{}
+	default:
+		panic(fmt.Sprintf("unknown selection strategy: %s", p.Selection))
+	}
+}
+
+// survivors returns the number of sorted genes which are carried into
+// the next generation before crossover fills the rest of it
+func (p *GA) survivors() int {
+	n := p.Elitism
+	if p.Selection == "" || p.Selection == SelectionTruncation {
+		ratio := p.TruncationRatio
+		if ratio <= 0 {
+			ratio = 0.25
+		}
+		n = max(n, int(float64(p.Size)*ratio), 1)
+	}
+	return min(n, len(p.Genes))
+}
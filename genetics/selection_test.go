@@ -0,0 +1,59 @@
+package genetics
+
+import (
+	"testing"
+
+	"github.com/andey-robins/magical/parsers/blif"
+)
+
+func TestElitismProtectsBestGenes(t *testing.T) {
+	g := blif.LoadBlifAsGraph("../input/circuits/cm150a_128.blif")
+
+	for _, selection := range SelectionNames() {
+		pop := NewGA(20, 1, 1.0, g, 1, 0, "")
+		pop.Selection = selection
+		pop.Elitism = 2
+
+		for i := 0; i < 5; i++ {
+			pop.nextEpoch(g)
+
+			best := pop.BestGene.Sequence.GetSequence()
+			elite := pop.Genes[0].Sequence.GetSequence()
+			for j := range best {
+				if best[j] != elite[j] {
+					t.Fatalf("%s: expected the best gene to survive unchanged", selection)
+				}
+			}
+
+			if len(pop.Genes) != pop.Size {
+				t.Errorf("%s: expected population of %d, got %d", selection, pop.Size, len(pop.Genes))
+			}
+		}
+	}
+}
+
+func TestSelectorsPreferBetterGenes(t *testing.T) {
+	pool := make([]*Gene, 10)
+	for i := range pool {
+		pool[i] = &Gene{Fitness: 10 + i}
+	}
+
+	selectors := map[string]Selector{
+		SelectionTournament: tournamentSelector{3},
+		SelectionRank:       rankSelector{},
+		SelectionRoulette:   rouletteSelector{},
+	}
+
+	for name, selector := range selectors {
+		rng := NewRNG(1).Rand
+		counts := make(map[*Gene]int)
+		for i := 0; i < 10_000; i++ {
+			counts[selector.Select(pool, rng)]++
+		}
+
+		if counts[pool[0]] <= counts[pool[len(pool)-1]] {
+			t.Errorf("%s: expected the best gene to be picked more often than the worst, got %d and %d",
+				name, counts[pool[0]], counts[pool[len(pool)-1]])
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package genetics
+
+import (
+	"context"
+	"time"
+
+	"github.com/andey-robins/magical/graph"
+)
+
+// Stop reasons record which rule ended a search, see Termination
+const (
+	StopEpsilon     = "epsilon"
+	StopGenerations = "generations"
+	StopTime        = "time"
+	StopEvaluations = "evaluations"
+	StopTarget      = "target"
+	StopLowerBound  = "lower-bound"
+	StopInterrupted = "interrupted"
+	StopError       = "error"
+)
+
+// Termination holds the rules which stop a search in addition to epsilon
+// generations without improvement. Each rule is disabled by its zero value
+// and the search stops as soon as any of them is met. The rules are checked
+// between generations, so a budget may be overrun by the generation which
+// was in progress when it ran out.
+//
+// Loop checks the rules once observers have been notified of a generation
+// and before it is checkpointed, so the checkpoint of the last
+// generation records StopReason. The last generation is also checkpointed
+// when CheckpointFreq doesn't divide it, so that a search which stopped
+// early resumes from where it stopped.
+type Termination struct {
+	MaxGenerations int           `json:"maxGenerations"` // counts generations from before a resume too
+	TimeLimit      time.Duration `json:"timeLimit"`      // applies to each call to Evolve
+	MaxEvaluations int           `json:"maxEvaluations"`
+	TargetFitness  int           `json:"targetFitness"` // stop once the best fitness is at most this
+
+	// StopAtBound stops once the best fitness reaches LowerBound, since no
+	// sequence can do better. LowerBound is computed with graph.LowerBound
+	// when the search begins if it is 0
+	StopAtBound bool `json:"stopAtBound"`
+	LowerBound  int  `json:"lowerBound"`
+
+	// Evaluations counts the fitness evaluations made while evolving and
+	// StopReason is one of the Stop constants once a search has stopped
+	Evaluations int    `json:"evaluations"`
+	StopReason  string `json:"stopReason"`
+
+	// Record is the best fitness when it last improved and Stagnant the
+	// number of generations since then. They are saved so that a resumed
+	// search stops after the same generation as one which wasn't stopped
+	Record   int `json:"record"`
+	Stagnant int `json:"stagnant"`
+
+	started time.Time
+}
+
+// BeginSearch starts the clock for TimeLimit and computes the lower bound
+// of `g` if it is needed. It is called at the start of Evolve with the best
+// fitness so far. A search which had stopped at epsilon starts counting
+// stagnant generations again, otherwise it resumes the count it stopped at
+func (t *Termination) BeginSearch(g *graph.Graph, best int) {
+	t.started = time.Now()
+	if t.Record == 0 {
+		t.Record = best
+	}
+	if t.StopReason == StopEpsilon {
+		t.Stagnant = 0
+	}
+	t.StopReason = ""
+	if t.StopAtBound && t.LowerBound == 0 {
+		t.LowerBound = g.LowerBound()
+	}
+}
+
+// Improve records the best fitness after a generation and returns true if
+// it improved on Record, otherwise the generation is counted as stagnant
+func (t *Termination) Improve(best int) bool {
+	if best < t.Record {
+		t.Record, t.Stagnant = best, 0
+		return true
+	}
+	t.Stagnant++
+	return false
+}
+
+// Elapsed returns the time since the search began
+func (t *Termination) Elapsed() time.Duration {
+	return time.Since(t.started)
+}
+
+// ShouldStop returns true once `ctx` is cancelled or any rule is met and
+// records which one in StopReason. `generations` is the number of
+// generations evolved so far, `best` the best fitness and `stagnant` the
+// number of generations since it last improved, which is stopped at `epsilon`
+func (t *Termination) ShouldStop(ctx context.Context, generations, best, stagnant, epsilon int) bool {
+	switch {
+	case ctx.Err() != nil:
+		t.StopReason = StopInterrupted
+	case t.StopAtBound && best <= t.LowerBound:
+		t.StopReason = StopLowerBound
+	case t.TargetFitness > 0 && best <= t.TargetFitness:
+		t.StopReason = StopTarget
+	case stagnant >= epsilon:
+		t.StopReason = StopEpsilon
+	case t.MaxGenerations > 0 && generations >= t.MaxGenerations:
+		t.StopReason = StopGenerations
+	case t.MaxEvaluations > 0 && t.Evaluations >= t.MaxEvaluations:
+		t.StopReason = StopEvaluations
+	case t.TimeLimit > 0 && t.Elapsed() >= t.TimeLimit:
+		t.StopReason = StopTime
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,87 @@
+package genetics
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/andey-robins/magical/graph"
+	"github.com/andey-robins/magical/parsers/blif"
+)
+
+func TestTerminationRules(t *testing.T) {
+	g := blif.LoadBlifAsGraph("../input/circuits/cm150a_128.blif")
+
+	tests := []struct {
+		name   string
+		rules  Termination
+		reason string
+	}{
+		{"generations", Termination{MaxGenerations: 3}, StopGenerations},
+		{"evaluations", Termination{MaxEvaluations: 50}, StopEvaluations},
+		{"target", Termination{TargetFitness: 1_000}, StopTarget},
+	}
+
+	for _, test := range tests {
+		pop := NewGA(20, 1_000, 0.2, g, 1, 0, "")
+		pop.Termination = test.rules
+		pop.Evolve(context.Background(), g)
+
+		if pop.StopReason != test.reason {
+			t.Errorf("%s: expected to stop for %s, got %s", test.name, test.reason, pop.StopReason)
+		}
+	}
+
+	pop := NewGA(20, 1_000, 0.2, g, 1, 0, "")
+	pop.MaxGenerations = 3
+	pop.Evolve(context.Background(), g)
+	if pop.Generations != 3 {
+		t.Errorf("expected 3 generations, got %d", pop.Generations)
+	}
+	if pop.Evaluations != 3*20 {
+		t.Errorf("expected %d evaluations, got %d", 3*20, pop.Evaluations)
+	}
+}
+
+func TestStopAtLowerBound(t *testing.T) {
+	// every sequence of this graph reaches its lower bound of 4
+	g := graph.LoadGraphFromString("Inputs 3\n1 2 3\nOutputs 1\n4\nNodes 4\nEdges 6\n1 5\n2 7\n3 6\n5 7\n6 4\n7 4")
+
+	pop := NewGA(10, 1_000, 0.2, g, 1, 0, "")
+	pop.StopAtBound = true
+	pop.Evolve(context.Background(), g)
+
+	if pop.StopReason != StopLowerBound || pop.LowerBound != pop.BestFitness {
+		t.Errorf("expected to stop at the lower bound %d, stopped for %s at %d", pop.LowerBound, pop.StopReason, pop.BestFitness)
+	}
+	if pop.Generations != 0 {
+		t.Errorf("expected to stop before evolving, got %d generations", pop.Generations)
+	}
+}
+
+func TestInterruptedEvolve(t *testing.T) {
+	g := blif.LoadBlifAsGraph("../input/circuits/cm150a_128.blif")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	pop := NewGA(20, 1_000, 0.2, g, 1, 0, "")
+	pop.Evolve(ctx, g)
+	if pop.StopReason != StopInterrupted || pop.Generations != 0 {
+		t.Errorf("expected to stop before evolving, stopped for %s after %d generations", pop.StopReason, pop.Generations)
+	}
+}
+
+func TestEarlyStopSavesLastGeneration(t *testing.T) {
+	g := blif.LoadBlifAsGraph("../input/circuits/cm150a_128.blif")
+	dir := t.TempDir()
+
+	// the last generation falls between the checkpoints every 5 generations
+	pop := NewGA(20, 1_000, 0.2, g, 1, 5, dir)
+	pop.MaxGenerations = 3
+	pop.Evolve(context.Background(), g)
+
+	if _, err := os.Stat(dir + "/3.json"); err != nil {
+		t.Errorf("expected a checkpoint of the last generation: %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package genetics
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// WorkerCount returns the number of workers to use for a setting of `n`,
+// which is GOMAXPROCS when `n` isn't positive
+func WorkerCount(n int) int {
+	if n <= 0 {
+		return runtime.GOMAXPROCS(0)
+	}
+	return n
+}
+
+// Parallel calls `f` for every index from 0 to `n` on at most `workers`
+// goroutines, see WorkerCount, and waits for them to finish. Each worker
+// takes the next index until there are none left, so a population is
+// evaluated in batches of `workers` genes. A panic in `f` is returned as
+// an error rather than killing the process.
+//
+// Once a call fails no more indices are handed out. Indices are handed out
+// in order, so every index before a failed one has been started and the
+// error returned is always that of the first index which failed, however
+// the workers were scheduled
+func Parallel(workers, n int, f func(i int) error) error {
+	workers = min(WorkerCount(workers), n)
+	if workers <= 0 {
+		return nil
+	}
+
+	errs := make([]error, n)
+	var next atomic.Int64
+	var failed atomic.Bool
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for !failed.Load() {
+				i := int(next.Add(1) - 1)
+				if i >= n {
+					return
+				}
+				if errs[i] = protect(i, f); errs[i] != nil {
+					failed.Store(true)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// protect calls f(i), turning a panic into an error
+func protect(i int, f func(i int) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return f(i)
+}
@@ -0,0 +1,114 @@
+package genetics
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andey-robins/magical/parsers/blif"
+	"github.com/andey-robins/magical/sequence"
+)
+
+func TestParallel(t *testing.T) {
+	var mu sync.Mutex
+	running, most := 0, 0
+	visited := make([]bool, 100)
+
+	err := Parallel(3, len(visited), func(i int) error {
+		mu.Lock()
+		running++
+		most = max(most, running)
+		visited[i] = true
+		mu.Unlock()
+
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return nil
+	})
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if most > 3 {
+		t.Errorf("expected at most 3 workers at once, got %d", most)
+	}
+	for i, ok := range visited {
+		if !ok {
+			t.Errorf("expected index %d to be visited", i)
+		}
+	}
+}
+
+func TestParallelReturnsFirstError(t *testing.T) {
+	for workers := 1; workers <= 8; workers++ {
+		err := Parallel(workers, 50, func(i int) error {
+			if i == 40 {
+				panic("forty")
+			}
+			if i%10 == 7 {
+				return errors.New(strings.Repeat("x", i))
+			}
+			return nil
+		})
+		if err == nil || err.Error() != "xxxxxxx" {
+			t.Errorf("%d workers: expected the error of index 7, got %v", workers, err)
+		}
+	}
+
+	err := Parallel(4, 10, func(i int) error {
+		if i == 5 {
+			panic("five")
+		}
+		return nil
+	})
+	if err == nil || err.Error() != "panic: five" {
+		t.Errorf("expected the panic to be returned, got %v", err)
+	}
+}
+
+func TestWorkersDontChangeEvolution(t *testing.T) {
+	g := blif.LoadBlifAsGraph("../input/circuits/cm150a_128.blif")
+
+	var best *Gene
+	for _, workers := range []int{1, 3, 0} {
+		pop := NewGA(20, 5, 0.5, g, 1, 0, "")
+		pop.LocalSearch = LocalSearchSteepest
+		pop.Workers = workers
+		if err := pop.Evolve(context.Background(), g); err != nil {
+			t.Fatalf("%d workers: %v", workers, err)
+		}
+
+		if best == nil {
+			best = pop.BestGene
+		} else if !reflect.DeepEqual(best, pop.BestGene) {
+			t.Errorf("%d workers: expected the same best gene as 1 worker", workers)
+		}
+	}
+}
+
+func TestEvolveReturnsEvaluationErrors(t *testing.T) {
+	g := blif.LoadBlifAsGraph("../input/circuits/cm150a_128.blif")
+
+	pop := NewGA(20, 5, 0.2, g, 1, 0, "")
+	// every node comes before its parents
+	seq := pop.Genes[3].Sequence.GetSequence()
+	reversed := make([]int, len(seq))
+	for i, id := range seq {
+		reversed[len(seq)-1-i] = id
+	}
+	pop.Genes[3] = &Gene{Sequence: sequence.NewSequence(reversed)}
+
+	err := pop.Evolve(context.Background(), g)
+	if err == nil || !strings.Contains(err.Error(), "invalid sequence") {
+		t.Errorf("expected an invalid sequence error, got %v", err)
+	}
+	if pop.StopReason != StopError || pop.Generations != 0 {
+		t.Errorf("expected to stop with an error before the first generation, got %s after %d", pop.StopReason, pop.Generations)
+	}
+}
@@ -0,0 +1,278 @@
+package graph
+
+import (
+	"github.com/andey-robins/magical/memory"
+)
+
+// Bounds are lower bounds on the footprint of every valid sequence of a
+// graph, each from a different argument. The best lower bound is their Max
+type Bounds struct {
+	// Inputs is the footprint of loading the inputs, which every
+	// simulation does before processing the sequence
+	Inputs int `json:"inputs"`
+
+	// InDegree is one more than the most distinct parents of any gate. The
+	// parents are all live when the gate is written to a new cell
+	InDegree int `json:"inDegree"`
+
+	// Cut is one more than the fewest nodes which separate the inputs from
+	// the outputs. Until the first output is processed, the live cells
+	// separate every output from the inputs, and the first output is then
+	// written to a new cell
+	Cut int `json:"cut"`
+
+	// Tree is the largest footprint needed to compute a gate whose fan-in
+	// cone is a tree, in the style of Sethi-Ullman numbers. Computing the
+	// cone takes at least that many cells however it is interleaved with
+	// the rest of the graph
+	Tree int `json:"tree"`
+}
+
+// Max returns the best of the lower bounds
+func (b Bounds) Max() int {
+	return max(b.Inputs, b.InDegree, b.Cut, b.Tree)
+}
+
+// LowerBound returns the best lower bound on the footprint of any valid
+// sequence of g, see Bounds
+func (g *Graph) LowerBound() int {
+	return g.Bounds().Max()
+}
+
+// Bounds computes each of the lower bounds on the footprint of g
+func (g *Graph) Bounds() Bounds {
+	return Bounds{
+		Inputs:   g.inputsBound(),
+		InDegree: g.inDegreeBound(),
+		Cut:      g.cutBound(),
+		Tree:     g.treeBound(),
+	}
+}
+
+// inputsBound simulates loading the inputs of g
+func (g *Graph) inputsBound() int {
+	mem := memory.NewMemory()
+	for _, node := range g.GetInputNodes() {
+		mem.ProcessNode(node.id, node.GetChildCount(), []int{})
+	}
+	return mem.GetMaxUtilization()
+}
+
+func (g *Graph) inDegreeBound() int {
+	bound := 0
+	for _, node := range g.nodes {
+		if node.HasAnyParents() {
+			bound = max(bound, len(distinctIds(node.parents))+1)
+		}
+	}
+	return bound
+}
+
+// cutBound finds the minimum vertex cut between the inputs and the outputs
+// as a maximum flow. Each node is split into an entry and an exit joined by
+// an edge of capacity 1, or of unlimited capacity for outputs since they
+// aren't live before the first output is processed
+func (g *Graph) cutBound() int {
+	const unlimited = 1 << 30
+
+	// node i has entry 2i and exit 2i+1, followed by the source and sink
+	number := make(map[int]int)
+	for _, node := range g.nodes {
+		if _, ok := number[node.id]; !ok {
+			number[node.id] = len(number)
+		}
+	}
+	source, sink := 2*len(number), 2*len(number)+1
+
+	capacity := make(map[[2]int]int)
+	adjacent := make(map[int][]int)
+	addEdge := func(from, to, c int) {
+		if _, ok := capacity[[2]int{from, to}]; !ok {
+			adjacent[from] = append(adjacent[from], to)
+			adjacent[to] = append(adjacent[to], from)
+		}
+		capacity[[2]int{from, to}] += c
+	}
+
+	outputs := 0
+	for _, node := range g.nodes {
+		i := number[node.id]
+		isOutput := node.HasAnyParents() && !node.HasAnyChildren()
+
+		if isOutput {
+			addEdge(2*i, 2*i+1, unlimited)
+			addEdge(2*i+1, sink, unlimited)
+			outputs++
+		} else {
+			addEdge(2*i, 2*i+1, 1)
+		}
+		if !node.HasAnyParents() {
+			addEdge(source, 2*i, unlimited)
+		}
+		for _, child := range node.children {
+			addEdge(2*i+1, 2*number[child.id], unlimited)
+		}
+	}
+	if outputs == 0 {
+		return 0
+	}
+
+	// augment along shortest paths until the sink can't be reached
+	flow := 0
+	for {
+		previous := map[int]int{source: source}
+		queue := []int{source}
+		for len(queue) > 0 {
+			at := queue[0]
+			queue = queue[1:]
+			for _, next := range adjacent[at] {
+				if _, seen := previous[next]; !seen && capacity[[2]int{at, next}] > 0 {
+					previous[next] = at
+					queue = append(queue, next)
+				}
+			}
+		}
+		if _, reached := previous[sink]; !reached {
+			return flow + 1
+		}
+
+		// every path crosses an edge of capacity 1, so each adds one to the flow
+		for at := sink; at != source; at = previous[at] {
+			capacity[[2]int{previous[at], at}]--
+			capacity[[2]int{at, previous[at]}]++
+		}
+		flow++
+	}
+}
+
+// treeBound returns the largest cost of computing a gate whose fan-in cone
+// is a tree, where no node of the cone has two children in the cone
+func (g *Graph) treeBound() int {
+	cost := make(map[int]int)
+	leaves := make(map[int]int)
+	notTree := make(map[int]bool)
+
+	// isTree reports whether the cone of `node` is a tree and records the
+	// cost and number of leaves of every tree cone it finds
+	var isTree func(node *Node) bool
+	isTree = func(node *Node) bool {
+		if _, ok := cost[node.id]; ok {
+			return true
+		}
+		if notTree[node.id] {
+			return false
+		}
+		if !node.HasAnyParents() {
+			cost[node.id], leaves[node.id] = 1, 1
+			return true
+		}
+
+		// the cones of the parents must be disjoint trees
+		seen := make(map[int]bool)
+		for _, parent := range node.parents {
+			if !isTree(parent) {
+				notTree[node.id] = true
+				return false
+			}
+			for id := range coneIds(parent) {
+				if seen[id] {
+					notTree[node.id] = true
+					return false
+				}
+				seen[id] = true
+			}
+		}
+
+		costs := make([]int, len(node.parents))
+		counts := make([]int, len(node.parents))
+		for i, parent := range node.parents {
+			costs[i], counts[i] = cost[parent.id], leaves[parent.id]
+			leaves[node.id] += counts[i]
+		}
+		cost[node.id] = treeCost(costs, counts)
+		return true
+	}
+
+	bound := 0
+	for _, node := range g.nodes {
+		if node.HasAnyParents() && isTree(node) {
+			bound = max(bound, cost[node.id])
+		}
+	}
+	return bound
+}
+
+// treeCost returns the fewest cells needed to compute a gate from parents
+// whose cones are trees with the given costs and numbers of leaves. While
+// a parent is computed, the parents before it are each held in a cell and
+// the leaves of the parents after it are still live. Then every parent is
+// live while the gate is written. The best order is found by trying every
+// order for small gates; larger gates fall back to a weaker bound
+func treeCost(costs, leaves []int) int {
+	k := len(costs)
+	bound := k + 1
+	if k > 6 {
+		for _, c := range costs {
+			bound = max(bound, c)
+		}
+		return bound
+	}
+
+	order := make([]int, k)
+	for i := range order {
+		order[i] = i
+	}
+
+	best := -1
+	var permute func(i int)
+	permute = func(i int) {
+		if i == k {
+			peak, remaining := bound, 0
+			for _, p := range order {
+				remaining += leaves[p]
+			}
+			for held, p := range order {
+				remaining -= leaves[p]
+				peak = max(peak, costs[p]+held+remaining)
+			}
+			if best < 0 || peak < best {
+				best = peak
+			}
+			return
+		}
+		for j := i; j < k; j++ {
+			order[i], order[j] = order[j], order[i]
+			permute(i + 1)
+			order[i], order[j] = order[j], order[i]
+		}
+	}
+	permute(0)
+
+	return best
+}
+
+// coneIds returns the ids of `node` and all of its ancestors
+func coneIds(node *Node) map[int]bool {
+	cone := make(map[int]bool)
+	var visit func(n *Node)
+	visit = func(n *Node) {
+		if cone[n.id] {
+			return
+		}
+		cone[n.id] = true
+		for _, parent := range n.parents {
+			visit(parent)
+		}
+	}
+	visit(node)
+	return cone
+}
+
+// distinctIds returns the distinct ids of `nodes`
+func distinctIds(nodes []*Node) map[int]bool {
+	ids := make(map[int]bool)
+	for _, node := range nodes {
+		ids[node.id] = true
+	}
+	return ids
+}
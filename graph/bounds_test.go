@@ -0,0 +1,39 @@
+package graph
+
+import "testing"
+
+func TestBounds(t *testing.T) {
+	tests := []struct {
+		graphString string
+		expected    Bounds
+	}{
+		// a balanced tree needs a cell for every input and both halves
+		{"Inputs 4\n1 2 3 4\nOutputs 1\n5\nNodes 3\nEdges 6\n1 6\n2 6\n3 7\n4 7\n6 5\n7 5", Bounds{4, 3, 3, 5}},
+		// every input must be live until the first output is written
+		{"Inputs 3\n1 2 3\nOutputs 2\n4 5\nNodes 2\nEdges 4\n1 4\n2 4\n2 5\n3 5", Bounds{3, 3, 4, 3}},
+		// the cones of 6 and 4 share input 3 so only 5, 7 and 8 are trees
+		{"Inputs 3\n1 2 3\nOutputs 1\n4\nNodes 5\nEdges 10\n1 5\n2 5\n1 7\n3 7\n2 8\n3 8\n7 6\n8 6\n5 4\n6 4", Bounds{3, 3, 3, 3}},
+	}
+
+	for _, test := range tests {
+		g := LoadGraphFromString(test.graphString)
+		if bounds := g.Bounds(); bounds != test.expected {
+			t.Errorf("expected bounds %+v, got %+v", test.expected, bounds)
+		}
+	}
+}
+
+func TestLowerBoundIsBelowHeuristics(t *testing.T) {
+	g := LoadGraphFromString("Inputs 4\n1 2 3 4\nOutputs 1\n5\nNodes 3\nEdges 6\n1 6\n2 6\n3 7\n4 7\n6 5\n7 5")
+
+	for _, name := range HeuristicNames() {
+		h, _ := GetHeuristic(name)
+		mem, err := g.SimulateSequence(h(g))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bound := g.LowerBound(); bound > mem.GetMaxUtilization() {
+			t.Errorf("%s: lower bound %d is above footprint %d", name, bound, mem.GetMaxUtilization())
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package graph
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"sort"
+)
+
+// Fingerprint returns a SHA-256 hash of the structure of g, which is the
+// same for any file describing the same nodes and edges in any order. A
+// checkpoint records the fingerprint of its graph so that it isn't resumed
+// against a different one.
+func (g *Graph) Fingerprint() string {
+	nodes := g.GetNodes()
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].id < nodes[j].id
+	})
+
+	h := sha256.New()
+	buf := make([]byte, 8)
+	write := func(n int) {
+		binary.LittleEndian.PutUint64(buf, uint64(n))
+		h.Write(buf)
+	}
+
+	// each node is written as its id, its number of children and their ids
+	for _, node := range nodes {
+		children := node.GetChildIds()
+		sort.Ints(children)
+		write(node.id)
+		write(len(children))
+		for _, child := range children {
+			write(child)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -12,15 +12,29 @@ import (
 type Graph struct {
 	nodes []*Node
 	edges int
+	index map[int]*Node
 }
 
 // NewGraph will return a pointer to a new Graph object
 // with the given nodes and edges. We count the edges not
 // because we couldn't later, but to simplify the printing
 // of the graph to a string since the information is provided
-// in the input file.
+// in the input file. Nodes are indexed by id since every
+// simulation looks each node of a sequence up.
 func NewGraph(nodes []*Node, edges int) *Graph {
-	return &Graph{nodes, edges}
+	index := make(map[int]*Node)
+	for _, node := range nodes {
+		if _, ok := index[node.id]; !ok {
+			index[node.id] = node
+		}
+	}
+	return &Graph{nodes, edges, index}
+}
+
+// GetNodes will return a list of pointers to every node in g, in the
+// order they were listed when g was loaded
+func (g *Graph) GetNodes() []*Node {
+	return append(make([]*Node, 0, len(g.nodes)), g.nodes...)
 }
 
 // GetOutputNodes will return a list of pointers to the output nodes in g
@@ -52,10 +66,8 @@ func (g *Graph) GetInputNodes() []*Node {
 // GetNodeById will return a pointer to the node in g with the given id
 // If no node can be found with the given id, it will return an error.
 func (g *Graph) GetNodeById(id int) (*Node, error) {
-	for _, node := range g.nodes {
-		if node.id == id {
-			return node, nil
-		}
+	if node, ok := g.index[id]; ok {
+		return node, nil
 	}
 
 	return nil, fmt.Errorf("no node with id %d found in graph", id)
@@ -1,6 +1,10 @@
 package graph
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/andey-robins/magical/sequence"
+)
 
 func TestLoadGraph(t *testing.T) {
 	tests := []struct {
@@ -19,3 +23,46 @@ func TestLoadGraph(t *testing.T) {
 		}
 	}
 }
+
+func TestRepairSequence(t *testing.T) {
+	g := LoadGraphFromString("Inputs 3\n1 2 3\nOutputs 1\n4\nNodes 4\nEdges 6\n1 5\n2 7\n3 6\n5 7\n6 4\n7 4")
+
+	tests := []struct {
+		sequence []int
+		expected []int
+	}{
+		// already valid sequences are untouched
+		{[]int{5, 6, 7, 4}, []int{5, 6, 7, 4}},
+		// 7 depends on 5, so 5 is pulled forward
+		{[]int{7, 5, 4, 6}, []int{5, 7, 6, 4}},
+		// unknown ids, inputs and duplicates are dropped and missing gates appended
+		{[]int{6, 1, 9, 6, 5}, []int{6, 5, 7, 4}},
+	}
+
+	for _, test := range tests {
+		repaired := g.RepairSequence(sequence.NewSequence(test.sequence)).GetSequence()
+		if len(repaired) != len(test.expected) {
+			t.Errorf("Expected %v, got %v", test.expected, repaired)
+			continue
+		}
+		for i := range repaired {
+			if repaired[i] != test.expected[i] {
+				t.Errorf("Expected %v, got %v", test.expected, repaired)
+				break
+			}
+		}
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	g := LoadGraphFromString("Inputs 3\n1 2 3\nOutputs 1\n4\nNodes 4\nEdges 6\n1 5\n2 7\n3 6\n5 7\n6 4\n7 4")
+	reordered := LoadGraphFromString("Inputs 3\n3 1 2\nOutputs 1\n4\nNodes 4\nEdges 6\n7 4\n6 4\n5 7\n3 6\n2 7\n1 5")
+	different := LoadGraphFromString("Inputs 3\n1 2 3\nOutputs 1\n4\nNodes 4\nEdges 6\n1 5\n2 7\n3 6\n5 7\n6 4\n5 4")
+
+	if g.Fingerprint() != reordered.Fingerprint() {
+		t.Errorf("Expected the fingerprint not to depend on the order of the file")
+	}
+	if g.Fingerprint() == different.Fingerprint() {
+		t.Errorf("Expected graphs with different edges to have different fingerprints")
+	}
+}
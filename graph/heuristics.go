@@ -0,0 +1,200 @@
+package graph
+
+import (
+	"sort"
+
+	"github.com/andey-robins/magical/sequence"
+)
+
+// The heuristics in this file build a valid sequence deterministically in
+// a single pass. They are much faster than evolving a sequence and give a
+// baseline footprint to compare against, or good genes to seed a population.
+
+// A Heuristic deterministically constructs a valid sequence for a graph
+type Heuristic func(g *Graph) *sequence.Sequence
+
+// heuristics maps the names used on the command line to their heuristics
+var heuristics = map[string]Heuristic{
+	"greedy":       (*Graph).GreedySequence,
+	"sethi-ullman": (*Graph).SethiUllmanSequence,
+	"dfs":          (*Graph).DepthFirstSequence,
+}
+
+// HeuristicNames returns the names of every available heuristic
+func HeuristicNames() []string {
+	names := make([]string, 0, len(heuristics))
+	for name := range heuristics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetHeuristic returns the heuristic with the given name
+func GetHeuristic(name string) (Heuristic, bool) {
+	h, ok := heuristics[name]
+	return h, ok
+}
+
+// GreedySequence is a list scheduler which always processes the ready node
+// that leaves the fewest cells in use. That is the node whose processing
+// frees the most of its parents, less the cell its own value occupies if
+// anything depends on it. Ties go to the node listed first in the graph.
+func (g *Graph) GreedySequence() *sequence.Sequence {
+	isInput := make(map[int]bool)
+	for _, input := range g.GetInputNodes() {
+		isInput[input.id] = true
+	}
+
+	// references counts the children of each node still to be processed,
+	// the same way memory counts them, and waiting counts the parents of
+	// each gate which haven't been processed yet
+	references := make(map[int]int)
+	waiting := make(map[int]int)
+	for _, node := range g.nodes {
+		references[node.id] = node.GetChildCount()
+		if !isInput[node.id] {
+			for _, parent := range node.parents {
+				if !isInput[parent.id] {
+					waiting[node.id]++
+				}
+			}
+		}
+	}
+
+	// the cells freed by processing `node` less the cell it takes up
+	gain := func(node *Node) int {
+		uses := make(map[int]int)
+		for _, parent := range node.parents {
+			uses[parent.id]++
+		}
+
+		freed := 0
+		for id, n := range uses {
+			if references[id] == n {
+				freed++
+			}
+		}
+		if node.HasAnyChildren() {
+			freed--
+		}
+		return freed
+	}
+
+	position := make(map[int]int)
+	ready := make([]*Node, 0)
+	for i, node := range g.nodes {
+		position[node.id] = i
+		if !isInput[node.id] && waiting[node.id] == 0 {
+			ready = append(ready, node)
+		}
+	}
+
+	seq := make([]int, 0, len(g.nodes))
+	for len(ready) > 0 {
+		best, bestGain := 0, gain(ready[0])
+		for i, node := range ready {
+			gained := gain(node)
+			if gained > bestGain || (gained == bestGain && position[node.id] < position[ready[best].id]) {
+				best, bestGain = i, gained
+			}
+		}
+
+		node := ready[best]
+		ready = append(ready[:best], ready[best+1:]...)
+		seq = append(seq, node.id)
+
+		for _, parent := range node.parents {
+			references[parent.id]--
+		}
+		for _, child := range node.children {
+			waiting[child.id]--
+			if waiting[child.id] == 0 {
+				ready = append(ready, child)
+			}
+		}
+	}
+
+	return sequence.NewSequence(seq)
+}
+
+// SethiUllmanSequence processes the cone of each node in the order given by
+// Sethi-Ullman labels. The label of a node is the number of cells needed to
+// compute it from the inputs when its cone is a tree, and the cones of its
+// parents are computed from the most demanding to the least so that the
+// fewest finished values are held at once. Labels overestimate the cells
+// needed for cones which share nodes, but the order is still a good one.
+func (g *Graph) SethiUllmanSequence() *sequence.Sequence {
+	labels := make(map[int]int)
+
+	var label func(node *Node) int
+	label = func(node *Node) int {
+		if l, ok := labels[node.id]; ok {
+			return l
+		}
+		if !node.HasAnyParents() {
+			labels[node.id] = 0
+			return 0
+		}
+
+		needs := make([]int, 0, len(node.parents))
+		for _, parent := range node.parents {
+			needs = append(needs, label(parent))
+		}
+		sort.Sort(sort.Reverse(sort.IntSlice(needs)))
+
+		// the i-th parent is computed while the i parents before it are held
+		l := 1
+		for i, need := range needs {
+			l = max(l, need+i)
+		}
+		labels[node.id] = l
+		return l
+	}
+
+	byLabel := func(nodes []*Node) []*Node {
+		sorted := append(make([]*Node, 0, len(nodes)), nodes...)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return label(sorted[i]) > label(sorted[j])
+		})
+		return sorted
+	}
+
+	return g.postOrder(byLabel)
+}
+
+// DepthFirstSequence processes each output's cone in a depth first post
+// order, visiting the outputs and the parents of each node in the order
+// they are listed in the graph.
+func (g *Graph) DepthFirstSequence() *sequence.Sequence {
+	return g.postOrder(func(nodes []*Node) []*Node { return nodes })
+}
+
+// postOrder walks the graph depth first from its outputs and returns the
+// gates in post order, so every node comes after its parents. `order`
+// decides which order the outputs and each node's parents are visited in
+func (g *Graph) postOrder(order func([]*Node) []*Node) *sequence.Sequence {
+	visited := make(map[int]bool)
+	seq := make([]int, 0, len(g.nodes))
+
+	var visit func(node *Node)
+	visit = func(node *Node) {
+		if visited[node.id] {
+			return
+		}
+		visited[node.id] = true
+
+		for _, parent := range order(node.parents) {
+			visit(parent)
+		}
+		if node.HasAnyParents() {
+			seq = append(seq, node.id)
+		}
+	}
+
+	for _, output := range order(g.GetOutputNodes()) {
+		visit(output)
+	}
+
+	return sequence.NewSequence(seq)
+}
@@ -0,0 +1,55 @@
+package graph
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHeuristicsAreValid(t *testing.T) {
+	g := LoadGraphFromFile("../input/graphs/test.graph")
+
+	for _, name := range HeuristicNames() {
+		h, ok := GetHeuristic(name)
+		if !ok {
+			t.Fatalf("expected heuristic %s to exist", name)
+		}
+
+		s := h(g)
+		if !g.IsValidSequence(s) {
+			t.Errorf("%s: expected a valid sequence, got %v", name, s.GetSequence())
+		}
+		if !reflect.DeepEqual(s.GetSequence(), h(g).GetSequence()) {
+			t.Errorf("%s: expected the same sequence every time", name)
+		}
+	}
+}
+
+func TestHeuristicOrders(t *testing.T) {
+	// 4 = f(5, 6) where 5 = f(1, 2) needs one cell and 6 = f(7, 8) needs
+	// two, so Sethi-Ullman computes 6 first while DFS follows the edges
+	g := LoadGraphFromString("Inputs 3\n1 2 3\nOutputs 1\n4\nNodes 5\nEdges 10\n1 5\n2 5\n1 7\n3 7\n2 8\n3 8\n7 6\n8 6\n5 4\n6 4")
+
+	tests := []struct {
+		name     string
+		expected []int
+	}{
+		{"dfs", []int{5, 7, 8, 6, 4}},
+		{"sethi-ullman", []int{7, 8, 6, 5, 4}},
+	}
+
+	for _, test := range tests {
+		h, _ := GetHeuristic(test.name)
+		if s := h(g).GetSequence(); !reflect.DeepEqual(s, test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, s)
+		}
+	}
+}
+
+func TestGreedyFreesCells(t *testing.T) {
+	// 5 frees inputs 1 and 2 while 6 frees nothing, so greedy picks 5 first
+	g := LoadGraphFromString("Inputs 3\n1 2 3\nOutputs 1\n4\nNodes 3\nEdges 6\n3 6\n2 5\n1 5\n3 4\n6 4\n5 4")
+
+	if s := g.GreedySequence().GetSequence(); !reflect.DeepEqual(s, []int{5, 6, 4}) {
+		t.Errorf("expected [5 6 4], got %v", s)
+	}
+}
@@ -0,0 +1,160 @@
+package graph
+
+import (
+	"math/rand"
+
+	"github.com/andey-robins/magical/sequence"
+)
+
+// The mutations in this file all preserve precedence by construction, so
+// unlike SmartMutate they never need to retry. They return the sequence
+// unchanged when the randomly chosen node has no room to move.
+
+// InsertionWindow returns the range of positions [lo, hi] at which the node
+// at index `idx` of `seq` could be reinserted once it is removed from the
+// sequence. lo is just after its last parent and hi is just before its first
+// child, both counted in the sequence without the node.
+func (g *Graph) InsertionWindow(seq []int, idx int) (int, int) {
+	node, err := g.GetNodeById(seq[idx])
+	check(err)
+
+	position := make(map[int]int)
+	for i, id := range seq {
+		if i > idx {
+			i--
+		}
+		position[id] = i
+	}
+
+	lo, hi := 0, len(seq)-1
+	for _, parent := range node.parents {
+		if pos, ok := position[parent.id]; ok && pos+1 > lo {
+			lo = pos + 1
+		}
+	}
+	for _, child := range node.children {
+		if pos, ok := position[child.id]; ok && pos < hi {
+			hi = pos
+		}
+	}
+
+	return lo, hi
+}
+
+// Reinsert returns a copy of `seq` with the node at index `from` moved to
+// index `to` of the sequence without it. Use InsertionWindow to find the
+// positions that keep a valid sequence valid.
+func Reinsert(seq []int, from, to int) []int {
+	id := seq[from]
+	rest := append(append(make([]int, 0, len(seq)), seq[:from]...), seq[from+1:]...)
+
+	moved := append(make([]int, 0, len(seq)), rest[:to]...)
+	moved = append(moved, id)
+	return append(moved, rest[to:]...)
+}
+
+// InsertMutate will move a random node of `s` to a random position between
+// its last parent and its first child, the node's ASAP-ALAP window in the
+// current order.
+func (g *Graph) InsertMutate(s *sequence.Sequence, seed int) *sequence.Sequence {
+	rng := rand.New(rand.NewSource(int64(seed)))
+	seq := s.GetSequence()
+
+	idx := rng.Intn(len(seq))
+	lo, hi := g.InsertionWindow(seq, idx)
+
+	return sequence.NewSequence(Reinsert(seq, idx, lo+rng.Intn(hi-lo+1)))
+}
+
+// BlockMutate will take a random node of `s` along with every gate in its
+// fan-in cone and move them together as one contiguous block. Every parent
+// of a gate in the cone is in the cone or is an input, so the block can be
+// placed anywhere before the first node outside the cone that depends on it.
+func (g *Graph) BlockMutate(s *sequence.Sequence, seed int) *sequence.Sequence {
+	rng := rand.New(rand.NewSource(int64(seed)))
+	seq := s.GetSequence()
+
+	root, err := g.GetNodeById(seq[rng.Intn(len(seq))])
+	check(err)
+
+	// collect the gates in the fan-in cone of the root
+	cone := make(map[int]*Node)
+	var collect func(node *Node)
+	collect = func(node *Node) {
+		if _, ok := cone[node.id]; ok || !node.HasAnyParents() {
+			return
+		}
+		cone[node.id] = node
+		for _, parent := range node.parents {
+			collect(parent)
+		}
+	}
+	collect(root)
+
+	block := make([]int, 0, len(cone))
+	rest := make([]int, 0, len(seq))
+	for _, id := range seq {
+		if _, ok := cone[id]; ok {
+			block = append(block, id)
+		} else {
+			rest = append(rest, id)
+		}
+	}
+
+	// the block has to come before anything outside of it which depends on it
+	hi := len(rest)
+	for i, id := range rest {
+		node, err := g.GetNodeById(id)
+		check(err)
+		if dependsOnAny(node, cone) {
+			hi = i
+			break
+		}
+	}
+
+	pos := rng.Intn(hi + 1)
+	moved := append(make([]int, 0, len(seq)), rest[:pos]...)
+	moved = append(moved, block...)
+	return sequence.NewSequence(append(moved, rest[pos:]...))
+}
+
+// ReverseMutate will find a run of consecutive nodes in `s` starting at a
+// random point in which no node depends on another, and reverse a random
+// length prefix of it. Nodes in such a run can be executed in any order.
+func (g *Graph) ReverseMutate(s *sequence.Sequence, seed int) *sequence.Sequence {
+	rng := rand.New(rand.NewSource(int64(seed)))
+	seq := s.GetSequence()
+
+	start := rng.Intn(len(seq))
+	run := make(map[int]*Node)
+	end := start
+	for end < len(seq) {
+		node, err := g.GetNodeById(seq[end])
+		check(err)
+		if dependsOnAny(node, run) {
+			break
+		}
+		run[node.id] = node
+		end++
+	}
+
+	if end-start < 2 {
+		return sequence.NewSequence(seq)
+	}
+
+	end = start + 2 + rng.Intn(end-start-1)
+	for i, j := start, end-1; i < j; i, j = i+1, j-1 {
+		seq[i], seq[j] = seq[j], seq[i]
+	}
+	return sequence.NewSequence(seq)
+}
+
+// dependsOnAny returns true if any parent of `node` is in `nodes`
+func dependsOnAny(node *Node, nodes map[int]*Node) bool {
+	for _, parent := range node.parents {
+		if _, ok := nodes[parent.id]; ok {
+			return true
+		}
+	}
+	return false
+}
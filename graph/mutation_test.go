@@ -0,0 +1,73 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/andey-robins/magical/sequence"
+)
+
+func TestMutationsPreservePrecedence(t *testing.T) {
+	// two independent chains 1 -> 5 -> 6 and 2 -> 7 -> 8 joined at 4,
+	// with 3 feeding the first chain
+	g := LoadGraphFromString("Inputs 3\n1 2 3\nOutputs 1\n4\nNodes 5\nEdges 7\n1 5\n3 5\n5 6\n2 7\n7 8\n6 4\n8 4")
+
+	mutations := map[string]func(*sequence.Sequence, int) *sequence.Sequence{
+		"insert":  g.InsertMutate,
+		"block":   g.BlockMutate,
+		"reverse": g.ReverseMutate,
+	}
+
+	for name, mutate := range mutations {
+		changed := false
+		for seed := 0; seed < 50; seed++ {
+			original := g.SynthesizeRandomValidSequence(seed)
+			mutated := mutate(original, seed)
+
+			if len(mutated.Sequence) != len(original.Sequence) {
+				t.Fatalf("%s: expected %d nodes, got %v", name, len(original.Sequence), mutated.Sequence)
+			}
+			if !g.IsValidSequence(mutated) {
+				t.Errorf("%s: produced an invalid sequence %v from %v", name, mutated.Sequence, original.Sequence)
+			}
+			if mutated.ToString() != original.ToString() {
+				changed = true
+			}
+		}
+
+		if !changed {
+			t.Errorf("%s: never changed a sequence", name)
+		}
+	}
+}
+
+func TestInsertionWindow(t *testing.T) {
+	g := LoadGraphFromString("Inputs 3\n1 2 3\nOutputs 1\n4\nNodes 5\nEdges 7\n1 5\n3 5\n5 6\n2 7\n7 8\n6 4\n8 4")
+
+	// 7 must stay before 8, which sits at index 2 once 7 is removed
+	lo, hi := g.InsertionWindow([]int{5, 7, 6, 8, 4}, 1)
+	if lo != 0 || hi != 2 {
+		t.Errorf("Expected window [0, 2], got [%d, %d]", lo, hi)
+	}
+}
+
+func TestNeighborhood(t *testing.T) {
+	g := LoadGraphFromString("Inputs 3\n1 2 3\nOutputs 1\n4\nNodes 5\nEdges 7\n1 5\n3 5\n5 6\n2 7\n7 8\n6 4\n8 4")
+	seq := []int{5, 6, 7, 8, 4}
+
+	seen := make(map[string]bool)
+	for _, move := range g.Neighborhood(seq) {
+		neighbor := sequence.NewSequence(move.Apply(seq))
+		if !g.IsValidSequence(neighbor) {
+			t.Errorf("Move %v produced an invalid sequence %v", move, neighbor.Sequence)
+		}
+		if neighbor.ToString() == sequence.NewSequence(seq).ToString() {
+			t.Errorf("Move %v didn't change the sequence", move)
+		}
+		seen[neighbor.ToString()] = true
+	}
+
+	// 7 and 8 can each be moved ahead of 5 and 6 in turn
+	if !seen[sequence.NewSequence([]int{7, 5, 6, 8, 4}).ToString()] {
+		t.Errorf("Expected moving 7 to the front to be in the neighborhood")
+	}
+}
@@ -0,0 +1,52 @@
+package graph
+
+import "math/rand/v2"
+
+// A Move reinserts the node at index From of a sequence at index To of
+// the sequence without it, see Reinsert. Moving a node by one position
+// swaps it with its neighbor.
+type Move struct {
+	From int
+	To   int
+}
+
+// Apply returns a copy of `seq` with the move performed
+func (m Move) Apply(seq []int) []int {
+	return Reinsert(seq, m.From, m.To)
+}
+
+// Neighborhood returns every move which takes the valid sequence `seq`
+// to a different valid sequence. This is each node reinserted at every
+// other position of its insertion window, which includes every valid
+// swap of two adjacent nodes. Moves are ordered by the node they move
+// and then by the position it is moved to.
+func (g *Graph) Neighborhood(seq []int) []Move {
+	moves := make([]Move, 0)
+	for from := range seq {
+		lo, hi := g.InsertionWindow(seq, from)
+		for to := lo; to <= hi; to++ {
+			if to != from {
+				moves = append(moves, Move{from, to})
+			}
+		}
+	}
+	return moves
+}
+
+// RandomMove returns a move of a random node of the valid sequence `seq` to
+// a random other position of its insertion window, drawing from `rng`. It
+// returns false when the chosen node has no other position to move to.
+func (g *Graph) RandomMove(seq []int, rng *rand.Rand) (Move, bool) {
+	from := rng.IntN(len(seq))
+	lo, hi := g.InsertionWindow(seq, from)
+	if lo >= hi {
+		return Move{}, false
+	}
+
+	// skip over the node's current position
+	to := lo + rng.IntN(hi-lo)
+	if to >= from {
+		to++
+	}
+	return Move{from, to}, true
+}
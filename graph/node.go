@@ -18,6 +18,10 @@ func (n *Node) AddChild(child *Node) {
 	n.children = append(n.children, child)
 }
 
+func (n *Node) GetId() int {
+	return n.id
+}
+
 func (n *Node) GetParentIds() []int {
 	ids := make([]int, 0)
 
@@ -28,6 +32,16 @@ func (n *Node) GetParentIds() []int {
 	return ids
 }
 
+func (n *Node) GetChildIds() []int {
+	ids := make([]int, 0)
+
+	for _, child := range n.children {
+		ids = append(ids, child.id)
+	}
+
+	return ids
+}
+
 func (n *Node) GetChildCount() int {
 	return len(n.children)
 }
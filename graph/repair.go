@@ -0,0 +1,93 @@
+package graph
+
+import (
+	"container/heap"
+
+	"github.com/andey-robins/magical/sequence"
+)
+
+// RepairSequence will return a valid sequence for g which stays as close
+// as possible to the order of `s`. Nodes are scheduled one at a time by
+// always picking, among the nodes whose parents have all been processed,
+// the one which appears earliest in `s`. Ids which are not gates of g
+// and duplicates are dropped, and gates missing from `s` are placed after
+// every gate that was present. A sequence which is already valid for g
+// is returned unchanged.
+func (g *Graph) RepairSequence(s *sequence.Sequence) *sequence.Sequence {
+	isInput := make(map[int]bool)
+	for _, input := range g.GetInputNodes() {
+		isInput[input.id] = true
+	}
+
+	// the priority of a node is its position in s, or past the end
+	// of s if it wasn't present
+	priority := make(map[int]int)
+	for i, id := range s.GetSequence() {
+		if _, ok := priority[id]; !ok {
+			priority[id] = i
+		}
+	}
+	missing := len(s.Sequence)
+	for _, node := range g.nodes {
+		if _, ok := priority[node.id]; !ok && !isInput[node.id] {
+			priority[node.id] = missing
+			missing++
+		}
+	}
+
+	// count how many parents of each node are still waiting to be processed
+	waiting := make(map[int]int)
+	for _, node := range g.nodes {
+		if isInput[node.id] {
+			continue
+		}
+		for _, parent := range node.parents {
+			if !isInput[parent.id] {
+				waiting[node.id]++
+			}
+		}
+	}
+
+	ready := &readyQueue{priority: priority}
+	for _, node := range g.nodes {
+		if !isInput[node.id] && waiting[node.id] == 0 {
+			heap.Push(ready, node)
+		}
+	}
+
+	seq := make([]int, 0, len(priority))
+	for ready.Len() > 0 {
+		node := heap.Pop(ready).(*Node)
+		seq = append(seq, node.id)
+		for _, child := range node.children {
+			waiting[child.id]--
+			if waiting[child.id] == 0 {
+				heap.Push(ready, child)
+			}
+		}
+	}
+
+	return sequence.NewSequence(seq)
+}
+
+// readyQueue is a min-heap of nodes ordered by their priority
+type readyQueue struct {
+	nodes    []*Node
+	priority map[int]int
+}
+
+func (q *readyQueue) Len() int { return len(q.nodes) }
+
+func (q *readyQueue) Less(i, j int) bool {
+	return q.priority[q.nodes[i].id] < q.priority[q.nodes[j].id]
+}
+
+func (q *readyQueue) Swap(i, j int) { q.nodes[i], q.nodes[j] = q.nodes[j], q.nodes[i] }
+
+func (q *readyQueue) Push(x interface{}) { q.nodes = append(q.nodes, x.(*Node)) }
+
+func (q *readyQueue) Pop() interface{} {
+	node := q.nodes[len(q.nodes)-1]
+	q.nodes = q.nodes[:len(q.nodes)-1]
+	return node
+}
@@ -0,0 +1,204 @@
+package inspect
+
+// Inspection reads the checkpoints of any optimizer without resuming them,
+// to summarize a run, tabulate how a directory of checkpoints converged or
+// compare the populations of two checkpoints.
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/andey-robins/magical/checkpoint"
+	"github.com/andey-robins/magical/genetics"
+	"github.com/andey-robins/magical/graph"
+	"github.com/andey-robins/magical/optimize"
+)
+
+// A Checkpoint holds the state which the checkpoints of every optimizer
+// share. Genetic algorithms fill Genes, archipelagos fill Islands and
+// searches without a population fill Current
+type Checkpoint struct {
+	Path        string `json:"-"`
+	Algorithm   string `json:"algorithm"` // one of optimize.Algorithms()
+	Generations int    `json:"generations"`
+	Seed        int    `json:"seed"`
+	Epsilon     int    `json:"epsilon"`
+	TieBreaker  string `json:"tieBreaker"`
+
+	BestFitness   int                `json:"bestFitness"`
+	BestGene      *genetics.Gene     `json:"bestGene"`
+	AvgFitness    float64            `json:"avgFitness"`
+	MedianFitness float64            `json:"medianFitness"`
+	WorstFitness  int                `json:"worstFitness"`
+	Diversity     genetics.Diversity `json:"diversity"`
+
+	Genes   []*genetics.Gene `json:"genes"`
+	Islands []*Checkpoint    `json:"islands"`
+	Current *genetics.Gene   `json:"current"`
+
+	genetics.Termination
+	checkpoint.Metadata
+}
+
+// Read reads the checkpoint file `path`
+func Read(path string) *Checkpoint {
+	c := &Checkpoint{Path: path}
+	checkpoint.Load(path, c)
+	// genetic algorithms and archipelagos predate the algorithm field
+	if c.Algorithm == "" {
+		c.Algorithm = optimize.AlgorithmGA
+	}
+	return c
+}
+
+// ReadDir reads every checkpoint in the directory `dir` in the order of
+// their generations
+func ReadDir(dir string) ([]*Checkpoint, error) {
+	entries, err := checkpoint.List(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	checkpoints := make([]*Checkpoint, len(entries))
+	for i, entry := range entries {
+		checkpoints[i] = Read(entry.Path)
+	}
+	return checkpoints, nil
+}
+
+// Population returns the genes of the checkpoint, which are those of every
+// island of an archipelago and the current sequence of a search without a
+// population. Genes bred in the generation which was checkpointed haven't
+// been evaluated yet and have a fitness of 0, see Evaluate
+func (c *Checkpoint) Population() []*genetics.Gene {
+	switch {
+	case len(c.Islands) > 0:
+		genes := make([]*genetics.Gene, 0)
+		for _, island := range c.Islands {
+			genes = append(genes, island.Genes...)
+		}
+		return genes
+	case c.Current != nil:
+		return []*genetics.Gene{c.Current}
+	}
+	return c.Genes
+}
+
+// Evaluate sets the fitness of every gene of the population over `g`
+func (c *Checkpoint) Evaluate(g *graph.Graph) error {
+	for i, gene := range c.Population() {
+		fitness, secondary, err := genetics.Evaluate(g, gene.Sequence, c.TieBreaker)
+		if err != nil {
+			return fmt.Errorf("gene %d: %w", i, err)
+		}
+		gene.Fitness, gene.Secondary = fitness, secondary
+	}
+	return nil
+}
+
+// Stats describe the checkpoint as its optimizer reported the generation
+// to its observers, except for the time elapsed and the fitness cache,
+// which aren't checkpointed. The fitness of an archipelago is averaged
+// over its islands in the same way
+func (c *Checkpoint) Stats() genetics.Stats {
+	s := genetics.Stats{
+		Generation:    c.Generations,
+		BestFitness:   c.BestFitness,
+		AvgFitness:    c.AvgFitness,
+		MedianFitness: c.MedianFitness,
+		WorstFitness:  c.WorstFitness,
+		Diversity:     c.Diversity,
+		Evaluations:   c.Evaluations,
+		Seed:          c.Seed,
+		StopReason:    c.StopReason,
+	}
+
+	switch {
+	case len(c.Islands) > 0:
+		s.AvgFitness, s.MedianFitness, s.Diversity = 0, 0, genetics.Diversity{}
+		genes := 0
+		for _, island := range c.Islands {
+			s.AvgFitness += island.AvgFitness * float64(len(island.Genes))
+			s.MedianFitness += island.MedianFitness * float64(len(island.Genes))
+			s.WorstFitness = max(s.WorstFitness, island.WorstFitness)
+			s.Diversity.Unique += island.Diversity.Unique
+			s.Diversity.Distance += island.Diversity.Distance * float64(len(island.Genes))
+			s.Diversity.Entropy += island.Diversity.Entropy * float64(len(island.Genes))
+			genes += len(island.Genes)
+		}
+		if genes > 0 {
+			s.AvgFitness /= float64(genes)
+			s.MedianFitness /= float64(genes)
+			s.Diversity.Distance /= float64(genes)
+			s.Diversity.Entropy /= float64(genes)
+		}
+	case c.Current != nil:
+		s.AvgFitness = float64(c.Current.Fitness)
+		s.MedianFitness = float64(c.Current.Fitness)
+		s.WorstFitness = c.Current.Fitness
+		s.Diversity = genetics.Diversity{Unique: 1}
+	}
+	return s
+}
+
+// A Comparison describes how the population changed between two
+// checkpoints. Shared counts the distinct sequences found in both
+// populations, out of UniqueA and UniqueB distinct sequences in each.
+// Fitness lists every fitness of either population along with the number
+// of genes with it in CountA and CountB. Genes which haven't been
+// evaluated aren't counted
+type Comparison struct {
+	Shared  int
+	UniqueA int
+	UniqueB int
+	Fitness []int
+	CountA  []int
+	CountB  []int
+}
+
+// Compare compares the populations of the checkpoints `a` and `b`
+func Compare(a, b *Checkpoint) Comparison {
+	seqsA, seqsB := sequences(a.Population()), sequences(b.Population())
+	cmp := Comparison{UniqueA: len(seqsA), UniqueB: len(seqsB)}
+	for seq := range seqsA {
+		if seqsB[seq] {
+			cmp.Shared++
+		}
+	}
+
+	histA, histB := histogram(a.Population()), histogram(b.Population())
+	for fitness := range histA {
+		cmp.Fitness = append(cmp.Fitness, fitness)
+	}
+	for fitness := range histB {
+		if _, ok := histA[fitness]; !ok {
+			cmp.Fitness = append(cmp.Fitness, fitness)
+		}
+	}
+	sort.Ints(cmp.Fitness)
+	for _, fitness := range cmp.Fitness {
+		cmp.CountA = append(cmp.CountA, histA[fitness])
+		cmp.CountB = append(cmp.CountB, histB[fitness])
+	}
+	return cmp
+}
+
+// sequences returns the set of distinct sequences of `genes`
+func sequences(genes []*genetics.Gene) map[string]bool {
+	seqs := make(map[string]bool)
+	for _, gene := range genes {
+		seqs[fmt.Sprint(gene.Sequence.GetSequence())] = true
+	}
+	return seqs
+}
+
+// histogram counts the evaluated genes of each fitness
+func histogram(genes []*genetics.Gene) map[int]int {
+	hist := make(map[int]int)
+	for _, gene := range genes {
+		if gene.Fitness != 0 {
+			hist[gene.Fitness]++
+		}
+	}
+	return hist
+}
@@ -0,0 +1,89 @@
+package inspect
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/andey-robins/magical/checkpoint"
+	"github.com/andey-robins/magical/genetics"
+	"github.com/andey-robins/magical/optimize"
+	"github.com/andey-robins/magical/parsers/blif"
+)
+
+func TestReadDir(t *testing.T) {
+	g := blif.LoadBlifAsGraph("../input/circuits/cm150a_128.blif")
+	dir := t.TempDir()
+
+	pop := genetics.NewGA(10, 5, 0.2, g, 1, 1, dir)
+	pop.MaxGenerations = 4
+	if err := pop.Evolve(context.Background(), g); err != nil {
+		t.Fatal(err)
+	}
+
+	checkpoints, err := ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checkpoints) != 5 {
+		t.Fatalf("expected 5 checkpoints, got %d", len(checkpoints))
+	}
+	for i, c := range checkpoints {
+		if c.Generations != i || c.Algorithm != optimize.AlgorithmGA {
+			t.Errorf("expected generation %d of the ga, got %d of %s", i, c.Generations, c.Algorithm)
+		}
+	}
+
+	last := checkpoints[len(checkpoints)-1].Stats()
+	if last.BestFitness != pop.BestFitness || last.StopReason != genetics.StopGenerations || last.Evaluations != pop.Evaluations {
+		t.Errorf("expected the stats of the run, got %+v", last)
+	}
+}
+
+func TestReadAnnealing(t *testing.T) {
+	g := blif.LoadBlifAsGraph("../input/circuits/cm150a_128.blif")
+	path := filepath.Join(t.TempDir(), "0.json")
+
+	checkpoint.Save(path, optimize.NewAnnealing(10, 5, g, 1, 0, ""))
+	c := Read(path)
+
+	if c.Algorithm != optimize.AlgorithmAnnealing || len(c.Population()) != 1 {
+		t.Errorf("expected annealing with a single sequence, got %s with %d", c.Algorithm, len(c.Population()))
+	}
+	if s := c.Stats(); s.WorstFitness != c.Current.Fitness || s.Diversity.Unique != 1 {
+		t.Errorf("expected the stats of the current sequence, got %+v", s)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	g := blif.LoadBlifAsGraph("../input/circuits/cm150a_128.blif")
+	dir := t.TempDir()
+
+	pop := genetics.NewGA(10, 5, 0.2, g, 1, 3, dir)
+	pop.MaxGenerations = 3
+	if err := pop.Evolve(context.Background(), g); err != nil {
+		t.Fatal(err)
+	}
+	a, b := Read(filepath.Join(dir, "0.json")), Read(filepath.Join(dir, "3.json"))
+
+	same := Compare(a, a)
+	if same.Shared != same.UniqueA || same.UniqueA != same.UniqueB {
+		t.Errorf("expected a checkpoint to share every sequence with itself, got %+v", same)
+	}
+
+	if err := b.Evaluate(g); err != nil {
+		t.Fatal(err)
+	}
+	cmp := Compare(a, b)
+	countA, countB := 0, 0
+	for i := range cmp.Fitness {
+		countA += cmp.CountA[i]
+		countB += cmp.CountB[i]
+	}
+	if countA != len(a.Population()) || countB != len(b.Population()) {
+		t.Errorf("expected every gene to be counted, got %d and %d", countA, countB)
+	}
+	if cmp.Shared > min(cmp.UniqueA, cmp.UniqueB) {
+		t.Errorf("expected at most %d shared sequences, got %d", min(cmp.UniqueA, cmp.UniqueB), cmp.Shared)
+	}
+}
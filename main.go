@@ -5,7 +5,11 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"os"
+	"strings"
+	"time"
 
+	"github.com/andey-robins/magical/config"
 	"github.com/andey-robins/magical/drivers"
 )
 
@@ -14,19 +18,30 @@ func main() {
 		fmt.Println("Run with -help for help information.")
 	}
 
-	var graphFile, sequenceFile, out, resume, chkpath, config string
-	var help, verify, memory, evolve, verbose bool
-	var seed, population, epsilon, checkpointFreq int
-	var mutation float64
+	if len(os.Args) > 1 && os.Args[1] == "checkpoint" {
+		checkpointCommand(os.Args[2:])
+		return
+	}
+
+	var graphFile, sequenceFile, out, resume, chkpath, configFile, heuristic, tieBreaker, crossover, selection, mutators, topology, localSearch, algorithm, cooling, seedSequences, seedHeuristics, runLog, dedup string
+	var help, verify, memory, evolve, verbose, exact, stopAtBound, force, chkgzip bool
+	var seed, population, epsilon, checkpointFreq, chkkeep, chkevery, tournament, elitism, adaptWindow, restart, islands, migrate, migrants, lsGenes, lsSteps, tenure, candidates, maxGenerations, maxEvaluations, targetFitness, cacheSize, workers int
+	var timeLimit time.Duration
+	var mutation, crossoverRate, truncation, adaptBoost, adaptMax, reseed, temperature, coolingRate float64
 	flag.StringVar(&graphFile, "graph", "", "the path to a graph file")
 	flag.StringVar(&sequenceFile, "sequence", "", "the path to a sequence file")
 	flag.StringVar(&out, "out", "", "the path to an output file")
-	flag.StringVar(&resume, "resume", "", "use to resume from a checkpoint file")
+	flag.StringVar(&resume, "resume", "", "use to resume from a checkpoint file, or the latest checkpoint in a directory")
+	flag.BoolVar(&force, "force", false, "use to resume a checkpoint saved from a different graph or by a newer version")
+	flag.StringVar(&runLog, "runlog", "", "the path to a file to log every generation to, as CSV if it ends in .csv and JSON lines otherwise")
 
 	flag.BoolVar(&verify, "verify", false, "use to verify that a sequence is valid for a graph")
 	flag.BoolVar(&memory, "memory", false, "use to get the memory utilization of a sequence over a graph")
 	flag.BoolVar(&evolve, "evolve", false, "use to minimize the memory utilization of a sequence over a graph with genetic evolution")
-	flag.StringVar(&config, "config", "", "use to run from a config file -- must specify a config file path.")
+	flag.StringVar(&configFile, "config", "", "use to run from a config file -- must specify a config file path.")
+	flag.BoolVar(&exact, "exact", false, "use to find a sequence with the smallest possible footprint over a small graph")
+	flag.DurationVar(&timeLimit, "timelimit", 0, "the longest to search for, e.g. 30s or 5m, 0 for no limit")
+	flag.StringVar(&heuristic, "heuristic", "", "use to build a sequence over a graph with a deterministic heuristic [dfs, greedy, sethi-ullman]")
 	flag.BoolVar(&verbose, "verbose", false, "use to display verbose output")
 	flag.BoolVar(&help, "help", false, "use to display help text")
 
@@ -34,9 +49,47 @@ func main() {
 	flag.IntVar(&epsilon, "epsilon", 100, "the number of generations to keep running without any improvement")
 	flag.Float64Var(&mutation, "mutation", 0.2, "the chance of a mutation occuring in a sequence [0.0 - 1.0]")
 	flag.IntVar(&seed, "seed", 1, "the seed to use for the random number generator")
+	flag.StringVar(&mutators, "mutators", "swap", "the weighted mutation operators to use, e.g. swap:2,insert:1 [swap, insert, block, reverse]")
+	flag.StringVar(&crossover, "xover", "default", "the crossover operator to use [default, order, ppx, uniform, pmx]")
+	flag.Float64Var(&crossoverRate, "xoverrate", 1.0, "the chance of crossing two parents instead of cloning them [0.0 - 1.0]")
+	flag.StringVar(&selection, "selection", "truncation", "the strategy used to select parents [truncation, tournament, rank, roulette]")
+	flag.IntVar(&tournament, "tournament", 2, "the number of genes competing in each tournament for tournament selection")
+	flag.Float64Var(&truncation, "truncation", 0.25, "the fraction of the population surviving truncation selection [0.0 - 1.0]")
+	flag.IntVar(&elitism, "elitism", 0, "the number of best genes copied unchanged into the next generation")
+	flag.IntVar(&adaptWindow, "adaptwindow", 0, "the number of generations without improvement before mutation is boosted, 0 to disable")
+	flag.Float64Var(&adaptBoost, "adaptboost", 1.5, "the factor the mutation chance is multiplied by each time it is boosted")
+	flag.Float64Var(&adaptMax, "adaptmax", 1.0, "the largest mutation chance adaptive mutation will reach [0.0 - 1.0]")
+	flag.Float64Var(&reseed, "reseed", 0.0, "the fraction of the population replaced with random genes each time mutation is boosted [0.0 - 1.0]")
+	flag.IntVar(&restart, "restart", 0, "the number of generations without improvement before restarting all but the elite, 0 to disable")
+	flag.IntVar(&islands, "islands", 1, "the number of islands evolving concurrently with independent seeds")
+	flag.StringVar(&topology, "topology", "ring", "the topology islands migrate genes along [ring, full]")
+	flag.IntVar(&migrate, "migrate", 10, "the number of generations between migrations, 0 to disable")
+	flag.IntVar(&migrants, "migrants", 2, "the number of best genes each island sends to its neighbors")
+	flag.StringVar(&seedSequences, "seedseq", "", "a comma separated list of sequence files to seed the population with")
+	flag.StringVar(&seedHeuristics, "seedheuristics", "", "a comma separated list of heuristics to seed the population with [dfs, greedy, sethi-ullman]")
+	flag.StringVar(&algorithm, "algorithm", "ga", "the optimizer to minimize with [ga, annealing, tabu]")
+	flag.Float64Var(&temperature, "temperature", 1.0, "the initial temperature of simulated annealing")
+	flag.StringVar(&cooling, "cooling", "geometric", "the cooling schedule of simulated annealing [geometric, linear, logarithmic]")
+	flag.Float64Var(&coolingRate, "coolrate", 0.95, "the cooling rate of simulated annealing")
+	flag.IntVar(&tenure, "tenure", 10, "the number of iterations a moved node is tabu")
+	flag.IntVar(&candidates, "candidates", 0, "the number of moves tabu search evaluates each iteration, 0 for all of them")
+	flag.IntVar(&maxGenerations, "maxgen", 0, "the most generations to evolve for, 0 for no limit")
+	flag.IntVar(&maxEvaluations, "maxevals", 0, "the most fitness evaluations to make, 0 for no limit")
+	flag.IntVar(&targetFitness, "target", 0, "stop once the best fitness is at most this, 0 to disable")
+	flag.BoolVar(&stopAtBound, "stopatbound", false, "stop once the best fitness reaches the lower bound of the graph")
+	flag.StringVar(&localSearch, "localsearch", "none", "the local search applied to the best genes each generation [none, steepest, first]")
+	flag.IntVar(&lsGenes, "lsgenes", 1, "the number of best genes improved by local search each generation")
+	flag.IntVar(&lsSteps, "lssteps", 0, "the most moves local search makes on a gene each generation, 0 for no limit")
+	flag.StringVar(&dedup, "dedup", "none", "how duplicate genes are replaced at the end of each generation [none, random, mutate]")
+	flag.IntVar(&cacheSize, "cache", config.DefaultFitnessCache, "the number of sequences whose fitness is cached, 0 to disable")
+	flag.IntVar(&workers, "workers", 0, "the number of goroutines evaluating and mutating genes, 0 for GOMAXPROCS")
+	flag.StringVar(&tieBreaker, "tiebreak", "none", "the secondary fitness used to order sequences with equal footprint [none, area, peak-time]")
 
 	flag.IntVar(&checkpointFreq, "chkfreq", 1, "the number of generations between checkpoints")
 	flag.StringVar(&chkpath, "chkpath", "./checkpoints", "the path to a directory to save checkpoint files to")
+	flag.BoolVar(&chkgzip, "chkgzip", false, "use to compress checkpoint files with gzip")
+	flag.IntVar(&chkkeep, "chkkeep", 0, "the number of newest checkpoints to keep, 0 to keep every checkpoint")
+	flag.IntVar(&chkevery, "chkevery", 0, "with -chkkeep, also keep the checkpoint of every generation which is a multiple of this")
 
 	flag.Parse()
 
@@ -46,16 +99,23 @@ func main() {
 		}
 
 		pad()
-		fmt.Println(" Welcome to the SAGA CLI utility (v0.2.0)")
+		fmt.Printf(" Welcome to the SAGA CLI utility (v%s)\n", drivers.Version)
 		fmt.Println(" This code is licensed under GPLv3. Source on GitHub.")
 		pad()
 		fmt.Println(" Args:")
 		fmt.Println("  -graph:      The path to an input graph file")
 		fmt.Println("  -sequence:   The path to an input sequence file")
 		fmt.Println("  -out:        The path to an output file. Output will be to STDOUT if\n\t\t none is specified")
-		fmt.Println("  -resume:     The path to a checkpoint file to resume from. NOTE: This will override any other flags.")
+		fmt.Println("  -resume:     The path to a checkpoint file, or a checkpoint directory to resume its latest\n\t\t checkpoint. NOTE: This will override any other flags except -pop, -epsilon,\n\t\t -maxgen, -mutation, -selection and -xover, which change the resumed run.\n\t\t The graph is found from the checkpoint if -graph isn't given")
+		fmt.Println("  -force:      Resume a checkpoint even if it was saved from a different graph than\n\t\t -graph or by a newer version of SAGA")
 		fmt.Println("  -chkfreq:    The number of generations between checkpoints (default 1)")
 		fmt.Println("  -chkpath:    The path to a directory to save checkpoints to (default ./checkpoints)")
+		fmt.Println("  -chkgzip:    Compress checkpoints with gzip")
+		fmt.Println("  -chkkeep:    Keep only this many of the newest checkpoints, 0 to keep them all (default 0)")
+		fmt.Println("  -chkevery:   With -chkkeep, also keep every checkpoint whose generation is a\n\t\t multiple of this (default 0)")
+		fmt.Println("  -runlog:     The path to a file to log every generation of -evolve or -resume to.\n\t\t CSV if it ends in .csv and JSON lines otherwise")
+		fmt.Println("  -timelimit:  The longest to search for with -exact or -evolve, e.g. 30s or 5m, 0 for\n\t\t no limit (default 0)")
+		fmt.Println("  -workers:    The number of goroutines evaluating and mutating genes with -evolve or\n\t\t -resume, shared between islands. 0 uses GOMAXPROCS (default 0)")
 		pad()
 		fmt.Println(" Flags:")
 		fmt.Println("  -verify:     Use to verify that a sequence is valid for a graph.\n\t\tRequires graph and sequence arguments")
@@ -63,26 +123,73 @@ func main() {
 		fmt.Println("  -evolve:     Use to minimize the memory utilization of a sequence\n\t\t over a graph. Requires graph and sequence arguments")
 		fmt.Println("  -verbose:	Use to display verbose output")
 		fmt.Println("  -config:     Use to run from a config file -- must specify a config file path.")
+		fmt.Println("  -exact:      Use to find a sequence with the smallest possible footprint over a\n\t\t graph of a few dozen gates. Requires a graph argument. Reports the gap\n\t\t of the sequence argument and writes to out if they are given")
+		fmt.Println("  -heuristic:  Use to build a sequence over a graph with a deterministic heuristic.\n\t\t One of dfs (depth first from the outputs), greedy (frees the most cells)\n\t\t or sethi-ullman. Requires graph and out arguments")
 		fmt.Println("  -help:       Display this help text :)")
 		pad()
+		fmt.Println(" Commands:")
+		fmt.Println("  checkpoint:  Inspect checkpoints without resuming them, see checkpoint -help")
+		pad()
 		fmt.Println(" Genetics Arguments:")
 		fmt.Println("  -pop:         The size of the population to use for genetic algorithms (default 400)")
 		fmt.Println("  -epsilon:     The number of generations to keep running without any improvement (default 100)")
 		fmt.Println("  -mutation:    The chance of a mutation occuring in a sequence [0.0 - 1.0] (default 0.2)")
 		fmt.Println("  -seed:        The seed to use for the random number generator, set to 0 for random seed (default 1)")
+		fmt.Println("  -mutators:    The mutation operators to use with optional weights, e.g. swap:2,insert:1.\n\t\t swap exchanges peers, insert moves a node within the window between its\n\t\t parents and children, block moves a node with its fan-in cone and\n\t\t reverse reverses a run of independent nodes (default swap)")
+		fmt.Println("  -xover:       The crossover operator to use. One of default (one-point), order (OX),\n\t\t ppx (precedence preserving), uniform (uniform order-based) or pmx\n\t\t (partially mapped) (default default)")
+		fmt.Println("  -xoverrate:   The chance of crossing two parents instead of cloning them [0.0 - 1.0] (default 1.0)")
+		fmt.Println("  -selection:   The strategy used to select parents. One of truncation, tournament,\n\t\t rank or roulette (default truncation)")
+		fmt.Println("  -tournament:  The number of genes competing in each tournament (default 2)")
+		fmt.Println("  -truncation:  The fraction of the population surviving truncation selection (default 0.25)")
+		fmt.Println("  -elitism:     The number of best genes copied unchanged into the next generation\n\t\t and protected from mutation (default 0)")
+		fmt.Println("  -adaptwindow: The number of generations without improvement before the mutation\n\t\t chance is boosted and an extra mutation step is added, 0 to disable (default 0)")
+		fmt.Println("  -adaptboost:  The factor the mutation chance is multiplied by each time it is boosted (default 1.5)")
+		fmt.Println("  -adaptmax:    The largest mutation chance adaptive mutation will reach (default 1.0)")
+		fmt.Println("  -reseed:      The fraction of the population replaced with random genes each time\n\t\t mutation is boosted (default 0.0)")
+		fmt.Println("  -restart:     The number of generations without improvement before every gene but\n\t\t the elite is replaced with a random gene, 0 to disable (default 0)")
+		fmt.Println("  -islands:     The number of islands evolving concurrently, each with a seed derived\n\t\t from -seed (default 1)")
+		fmt.Println("  -topology:    The topology islands migrate genes along. One of ring or full (default ring)")
+		fmt.Println("  -migrate:     The number of generations between migrations, 0 to disable (default 10)")
+		fmt.Println("  -migrants:    The number of best genes each island sends to its neighbors (default 2)")
+		fmt.Println("  -localsearch: The local search applied to the best genes each generation. One of\n\t\t none, steepest (steepest descent) or first (first improvement) (default none)")
+		fmt.Println("  -lsgenes:     The number of best genes improved by local search each generation (default 1)")
+		fmt.Println("  -lssteps:     The most moves local search makes on a gene each generation, 0 to\n\t\t continue until a local optimum (default 0)")
+		fmt.Println("  -dedup:       How genes which duplicate another gene are replaced at the end of each\n\t\t generation. One of none, random (a fresh random gene) or mutate (a heavily\n\t\t mutated copy) (default none)")
+		fmt.Println("  -cache:       The number of recently evaluated sequences whose fitness is remembered\n\t\t so that they aren't simulated again, 0 to disable (default 10000)")
+		fmt.Println("  -tiebreak:    The secondary fitness used to order sequences with equal footprint. One of\n\t\t none, area (area under the utilization curve) or peak-time (steps spent at\n\t\t the peak footprint) (default none)")
+		fmt.Println("  -seedseq:     A comma separated list of sequence files which replace part of the\n\t\t random initial population. Sequences which aren't valid for the graph are\n\t\t repaired first")
+		fmt.Println("  -seedheuristics: A comma separated list of heuristics whose sequences replace part\n\t\t of the random initial population. Any of dfs, greedy or sethi-ullman")
+		pad()
+		fmt.Println(" Optimizer Arguments:")
+		fmt.Println("  -algorithm:   The optimizer to minimize with. One of ga (genetic algorithm), annealing\n\t\t (simulated annealing) or tabu (tabu search). -epsilon, -seed, -tiebreak and\n\t\t the checkpoint arguments apply to all of them (default ga)")
+		fmt.Println("  -temperature: The initial temperature of simulated annealing, which tries -pop moves\n\t\t at each temperature (default 1.0)")
+		fmt.Println("  -cooling:     The cooling schedule of simulated annealing. One of geometric, linear or\n\t\t logarithmic (default geometric)")
+		fmt.Println("  -coolrate:    The cooling rate of simulated annealing [0.0 - 1.0] (default 0.95)")
+		fmt.Println("  -tenure:      The number of iterations a node moved by tabu search is tabu (default 10)")
+		fmt.Println("  -candidates:  The number of random moves tabu search evaluates each iteration, 0 to\n\t\t evaluate every move (default 0)")
+		fmt.Println("  -maxgen:      The most generations to evolve for, 0 for no limit (default 0)")
+		fmt.Println("  -maxevals:    The most fitness evaluations to make, 0 for no limit (default 0)")
+		fmt.Println("  -target:      Stop once the best fitness is at most this, 0 to disable (default 0)")
+		fmt.Println("  -stopatbound: Stop once the best fitness reaches the lower bound of the graph, which\n\t\t proves it is optimal")
 		pad()
 		return
 	}
 
+	mutationOperators, err := config.ParseWeights(mutators)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
 	if !verbose {
 		log.SetOutput(io.Discard)
 	}
 
 	if resume != "" {
-		drivers.ResumeDriver(resume, graphFile, out)
+		drivers.ResumeDriver(resume, graphFile, out, runLog, workers, force, resumeOverrides(population, epsilon, maxGenerations, mutation, selection, crossover))
 
-	} else if config != "" {
-		drivers.ConfigDriver(config)
+	} else if configFile != "" {
+		drivers.ConfigDriver(configFile)
 
 	} else if verify {
 		drivers.VerifyDriver(graphFile, sequenceFile)
@@ -90,10 +197,136 @@ func main() {
 	} else if memory {
 		drivers.MemoryDriver(graphFile, sequenceFile)
 
+	} else if exact {
+		drivers.ExactDriver(graphFile, sequenceFile, out, timeLimit)
+
+	} else if heuristic != "" {
+		drivers.HeuristicDriver(graphFile, out, heuristic)
+
 	} else if evolve {
-		drivers.MinimizeDriver(graphFile, out, population, epsilon, seed, mutation, checkpointFreq, chkpath)
+		drivers.MinimizeDriver(graphFile, out, runLog, &config.Population{
+			Population:          population,
+			Epsilon:             epsilon,
+			MutationRate:        mutation,
+			Seed:                seed,
+			CheckpointFreq:      checkpointFreq,
+			CheckpointPath:      chkpath,
+			CheckpointCompress:  chkgzip,
+			CheckpointKeep:      chkkeep,
+			CheckpointKeepEvery: chkevery,
+			TieBreaker:          tieBreaker,
+			Crossover:           crossover,
+			CrossoverRate:       crossoverRate,
+			Selection:           selection,
+			TournamentSize:      tournament,
+			TruncationRatio:     truncation,
+			Elitism:             elitism,
+			MutationOperators:   mutationOperators,
+			StagnationWindow:    adaptWindow,
+			MutationBoost:       adaptBoost,
+			MaxMutation:         adaptMax,
+			ReseedFraction:      reseed,
+			RestartAfter:        restart,
+			Islands:             islands,
+			Topology:            topology,
+			MigrationInterval:   migrate,
+			Migrants:            migrants,
+			LocalSearch:         localSearch,
+			LocalSearchGenes:    lsGenes,
+			LocalSearchSteps:    lsSteps,
+			Dedup:               dedup,
+			FitnessCache:        cacheSize,
+			Workers:             workers,
+			Algorithm:           algorithm,
+			Temperature:         temperature,
+			Cooling:             cooling,
+			CoolingRate:         coolingRate,
+			TabuTenure:          tenure,
+			TabuCandidates:      candidates,
+			SeedSequences:       splitList(seedSequences),
+			SeedHeuristics:      splitList(seedHeuristics),
+			MaxGenerations:      maxGenerations,
+			TimeLimit:           timeLimit.String(),
+			MaxEvaluations:      maxEvaluations,
+			TargetFitness:       targetFitness,
+			StopAtBound:         stopAtBound,
+		})
 
 	} else {
 		fmt.Println("No valid flags specified. Run with -help for help information.")
 	}
 }
+
+// resumeOverrides collects the parameters which were given on the command
+// line to change them when a run is resumed, since every other flag is
+// ignored by -resume
+func resumeOverrides(population, epsilon, maxGenerations int, mutation float64, selection, crossover string) drivers.ResumeOverrides {
+	var o drivers.ResumeOverrides
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "pop":
+			o.Population = &population
+		case "epsilon":
+			o.Epsilon = &epsilon
+		case "maxgen":
+			o.MaxGenerations = &maxGenerations
+		case "mutation":
+			o.MutationRate = &mutation
+		case "selection":
+			o.Selection = &selection
+		case "xover":
+			o.Crossover = &crossover
+		}
+	})
+	return o
+}
+
+// checkpointCommand runs the checkpoint subcommand with the arguments after
+// its name. It summarizes a checkpoint, tabulates a checkpoint directory or
+// diffs two checkpoints
+func checkpointCommand(args []string) {
+	fs := flag.NewFlagSet("checkpoint", flag.ExitOnError)
+	var best, graphFile string
+	var diff bool
+	fs.StringVar(&best, "best", "", "the path to write the best sequence of the checkpoint to")
+	fs.BoolVar(&diff, "diff", false, "use to compare two checkpoints")
+	fs.StringVar(&graphFile, "graph", "", "the graph of the checkpoints, to compare the fitness of their populations")
+	fs.Usage = func() {
+		fmt.Println("Usage:")
+		fmt.Println("  saga checkpoint [-best out.seq] <checkpoint file or directory>")
+		fmt.Println("  saga checkpoint -diff [-graph g.blif] <checkpoint> <checkpoint>")
+		fmt.Println()
+		fmt.Println("A checkpoint file is summarized and a checkpoint directory is printed as a convergence")
+		fmt.Println("table. -best writes the best sequence of the checkpoint, or of the latest checkpoint in")
+		fmt.Println("a directory. -diff compares the statistics and populations of two checkpoints, which may")
+		fmt.Println("be directories to compare their latest checkpoints. The fitness of the populations is")
+		fmt.Println("compared when the graph is found from the checkpoint or given with -graph.")
+		fmt.Println()
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	switch {
+	case diff && fs.NArg() == 2:
+		drivers.DiffDriver(fs.Arg(0), fs.Arg(1), graphFile)
+	case !diff && fs.NArg() == 1:
+		drivers.InspectDriver(fs.Arg(0), best)
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+}
+
+// splitList splits a comma separated command line argument into its
+// entries, and returns nil for an empty argument
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	entries := strings.Split(s, ",")
+	for i, entry := range entries {
+		entries[i] = strings.TrimSpace(entry)
+	}
+	return entries
+}
@@ -8,11 +8,16 @@ type memCell struct {
 
 type Memory struct {
 	cells []*memCell
+
+	// utilization records the number of live cells at the moment each
+	// node was written, one entry per call to ProcessNode
+	utilization []int
 }
 
 func NewMemory() *Memory {
 	return &Memory{
-		cells: make([]*memCell, 0),
+		cells:       make([]*memCell, 0),
+		utilization: make([]int, 0),
 	}
 }
 
@@ -43,6 +48,7 @@ func (m *Memory) ProcessNode(nodeId, refCount int, parents []int) {
 		id:       nodeId,
 		refCount: refCount,
 	}
+	m.utilization = append(m.utilization, m.liveCells())
 
 	// decrement the refCount of the parents
 	// TODO: this is O(n^2) and could be improved if it becomes a bottleneck
@@ -69,6 +75,17 @@ func (m *Memory) markAndSweep() {
 	}
 }
 
+// liveCells returns the number of cells currently holding a valid value
+func (m *Memory) liveCells() int {
+	live := 0
+	for _, cell := range m.cells {
+		if cell.valid {
+			live++
+		}
+	}
+	return live
+}
+
 // GetMaxUtilization returns the maximum number of cells
 // that have been used in this Memory object. This is the
 // number of memristor cells that would be needed to compute
@@ -80,3 +97,35 @@ func (m *Memory) GetMaxUtilization() int {
 	// tracking it
 	return len(m.cells)
 }
+
+// GetUtilizationCurve returns a copy of the number of live cells at
+// each step of the simulation. Its maximum is the max utilization
+func (m *Memory) GetUtilizationCurve() []int {
+	curve := make([]int, 0)
+	curve = append(curve, m.utilization...)
+	return curve
+}
+
+// GetAreaUnderCurve returns the sum of the live cells over every step
+// of the simulation. Two sequences with the same peak footprint can be
+// told apart by how long they keep memory occupied
+func (m *Memory) GetAreaUnderCurve() int {
+	area := 0
+	for _, live := range m.utilization {
+		area += live
+	}
+	return area
+}
+
+// GetTimeAtPeak returns the number of steps in which the simulation
+// used every allocated cell. Fewer steps at the peak means fewer places
+// a sequence has to be changed to lower its footprint
+func (m *Memory) GetTimeAtPeak() int {
+	steps := 0
+	for _, live := range m.utilization {
+		if live == m.GetMaxUtilization() {
+			steps++
+		}
+	}
+	return steps
+}
@@ -40,3 +40,31 @@ func TestMemory(t *testing.T) {
 		t.Errorf("Max memory utilization should be 2. got=%d", mem.GetMaxUtilization())
 	}
 }
+
+func TestUtilizationCurve(t *testing.T) {
+	mem := NewMemory()
+
+	mem.ProcessNode(1, 1, []int{})
+	mem.ProcessNode(2, 1, []int{})
+	mem.ProcessNode(3, 1, []int{1})
+	mem.ProcessNode(4, 1, []int{2, 3})
+
+	expected := []int{1, 2, 3, 3}
+	curve := mem.GetUtilizationCurve()
+	if len(curve) != len(expected) {
+		t.Fatalf("Expected curve of length %d, got %d", len(expected), len(curve))
+	}
+	for i := range expected {
+		if curve[i] != expected[i] {
+			t.Errorf("Expected utilization %d at step %d, got %d", expected[i], i, curve[i])
+		}
+	}
+
+	if mem.GetAreaUnderCurve() != 9 {
+		t.Errorf("Expected area under curve 9, got %d", mem.GetAreaUnderCurve())
+	}
+
+	if mem.GetTimeAtPeak() != 2 {
+		t.Errorf("Expected 2 steps at peak, got %d", mem.GetTimeAtPeak())
+	}
+}
@@ -0,0 +1,246 @@
+package optimize
+
+import (
+	"context"
+	"log"
+	"math"
+
+	"github.com/andey-robins/magical/checkpoint"
+	"github.com/andey-robins/magical/genetics"
+	"github.com/andey-robins/magical/graph"
+	"github.com/andey-robins/magical/sequence"
+)
+
+// Cooling schedules give the temperature after k temperature steps from
+// the initial temperature T0 and the cooling rate a. Geometric cooling
+// gives T0 * a^k, linear cooling gives T0 * (1 - (1-a)k) until it reaches
+// 0, and logarithmic cooling gives T0 / (1 + (1-a)ln(1+k)).
+const (
+	CoolingGeometric   = "geometric"
+	CoolingLinear      = "linear"
+	CoolingLogarithmic = "logarithmic"
+)
+
+// CoolingSchedules returns the names of every available cooling schedule
+func CoolingSchedules() []string {
+	return []string{CoolingGeometric, CoolingLinear, CoolingLogarithmic}
+}
+
+// Annealing is a simulated annealing search over the same moves as local
+// search, see graph.Neighborhood. At each temperature it tries Moves random
+// moves of the current sequence, always accepting moves which don't make it
+// worse and accepting a move which raises the footprint by d with
+// probability e^(-d/T).
+type Annealing struct {
+	Algorithm   string         `json:"algorithm"` // always AlgorithmAnnealing, identifies checkpoints
+	Current     *genetics.Gene `json:"current"`
+	BestFitness int            `json:"bestFitness"`
+	BestGene    *genetics.Gene `json:"bestGene"`
+	Generations int            `json:"generations"` // the number of temperature steps taken
+	Moves       int            `json:"moves"`       // the number of moves tried at each temperature
+	Accepted    int            `json:"accepted"`    // the number of moves accepted at the last temperature
+	Seed        int            `json:"seed"`
+	RNG         *genetics.RNG  `json:"rng"`
+	TieBreaker  string         `json:"tieBreaker"` // one of genetics.TieBreakers()
+
+	// Temperature is the initial temperature, which is lowered according
+	// to Cooling, one of CoolingSchedules(), at CoolingRate
+	Temperature float64 `json:"temperature"`
+	Cooling     string  `json:"cooling"`
+	CoolingRate float64 `json:"coolingRate"`
+
+	// Termination stops the search early, see genetics.Termination
+	genetics.Termination
+
+	// Metadata describes the run in its checkpoints
+	checkpoint.Metadata
+
+	observers genetics.Observers
+
+	// the number of temperature steps we will continue searching without improvements
+	Epsilon          int               `json:"epsilon"`
+	CheckpointFreq   int               `json:"checkpointFreq"` // set to 0 to disable checkpoints
+	CheckpointPath   string            `json:"checkpointPath"`
+	CheckpointPolicy checkpoint.Policy `json:"checkpointPolicy"`
+}
+
+// NewAnnealing will create a simulated annealing search over `g` which
+// starts from a random valid sequence and tries `moves` moves at each
+// temperature. It starts at a temperature of 1 with geometric cooling at a
+// rate of 0.95; set the exported fields before evolving to change this.
+func NewAnnealing(moves, e int, g *graph.Graph, seed int, checkpointFreq int, chkpath string) *Annealing {
+	rng := genetics.NewRNG(seed)
+	current := newGene(g, g.SynthesizeRandomValidSequence(rng.Int()).GetSequence(), genetics.TieBreakerNone)
+	best := *current
+
+	return &Annealing{
+		Algorithm:      AlgorithmAnnealing,
+		Current:        current,
+		BestFitness:    best.Fitness,
+		BestGene:       &best,
+		Moves:          moves,
+		Seed:           seed,
+		Temperature:    1,
+		Cooling:        CoolingGeometric,
+		CoolingRate:    0.95,
+		RNG:            rng,
+		Epsilon:        e,
+		CheckpointFreq: checkpointFreq,
+		CheckpointPath: chkpath,
+	}
+}
+
+// Evolve will anneal until we have gone `epsilon` temperature steps without
+// improving the best fitness, until one of the rules of a.Termination is met
+// or until `ctx` is cancelled. If a move fails to evaluate the search stops
+// without checkpointing the temperature step and the error is returned
+func (a *Annealing) Evolve(ctx context.Context, g *graph.Graph) error {
+	if a.RNG == nil {
+		a.SynchronizeRNG()
+	}
+	a.BeginSearch(g, a.BestFitness)
+
+	if err := reevaluate(g, a.TieBreaker, a.Current, a.BestGene); err != nil {
+		return err
+	}
+
+	loop := genetics.Loop{
+		Unit:        "temperature step",
+		Termination: &a.Termination,
+		Observers:   a.observers,
+		Epsilon:     a.Epsilon,
+		Step: func() (bool, error) {
+			if err := a.step(g); err != nil {
+				return false, err
+			}
+			return a.Improve(a.BestFitness), nil
+		},
+		Stats: a.stats,
+		Best: func() *genetics.Gene {
+			return a.BestGene
+		},
+		Report: func() {
+			log.Printf("Epoch %d: Best fitness: %d Current fitness: %d Temperature: %.4f Accepted: %d/%d\n",
+				a.Generations, a.BestFitness, a.Current.Fitness, a.temperature(a.Generations-1), a.Accepted, a.Moves)
+		},
+		Checkpoint:       a,
+		CheckpointFreq:   a.CheckpointFreq,
+		CheckpointPath:   a.CheckpointPath,
+		CheckpointPolicy: a.CheckpointPolicy,
+	}
+	return loop.Run(ctx)
+}
+
+// step tries Moves random moves at the current temperature and then cools
+//
+// This function uses random numbers, but pulls from a.RNG which is seeded
+// deterministically and doesn't spawn any go-routines
+func (a *Annealing) step(g *graph.Graph) error {
+	t := a.temperature(a.Generations)
+	a.Accepted = 0
+
+	for i := 0; i < a.Moves; i++ {
+		seq := a.Current.Sequence.GetSequence()
+		move, ok := g.RandomMove(seq, a.RNG.Rand)
+		if !ok {
+			continue
+		}
+
+		candidate, err := evaluateGene(g, move.Apply(seq), a.TieBreaker)
+		if err != nil {
+			return err
+		}
+		a.Evaluations++
+		if !a.accept(a.Current, candidate, t) {
+			continue
+		}
+
+		a.Current = candidate
+		a.Accepted++
+		if genetics.Less(candidate, a.BestGene) {
+			best := *candidate
+			a.BestFitness = best.Fitness
+			a.BestGene = &best
+		}
+	}
+
+	a.Generations++
+	return nil
+}
+
+// accept decides whether to move from `current` to `candidate` at
+// temperature `t`. Moves which only change the secondary fitness are
+// always accepted so that the search can cross plateaus
+func (a *Annealing) accept(current, candidate *genetics.Gene, t float64) bool {
+	delta := candidate.Fitness - current.Fitness
+	if delta <= 0 {
+		return true
+	}
+	if t <= 0 {
+		return false
+	}
+	return a.RNG.Float64() < math.Exp(-float64(delta)/t)
+}
+
+// temperature returns the temperature after `k` temperature steps
+func (a *Annealing) temperature(k int) float64 {
+	decay := 1 - a.CoolingRate
+	switch a.Cooling {
+	case "", CoolingGeometric:
+		return a.Temperature * math.Pow(a.CoolingRate, float64(k))
+	case CoolingLinear:
+		return a.Temperature * math.Max(0, 1-decay*float64(k))
+	case CoolingLogarithmic:
+		return a.Temperature / (1 + decay*math.Log(1+float64(k)))
+	default:
+		panic("unknown cooling schedule: " + a.Cooling)
+	}
+}
+
+// SeedSequences starts the search from the best of `seqs`
+func (a *Annealing) SeedSequences(g *graph.Graph, seqs []*sequence.Sequence) {
+	seed := bestSeed(g, seqs, a.TieBreaker)
+	if seed == nil {
+		return
+	}
+
+	a.Current = seed
+	if genetics.Less(seed, a.BestGene) {
+		best := *seed
+		a.BestFitness = best.Fitness
+		a.BestGene = &best
+	}
+}
+
+// AddObserver adds an observer which is notified of the progress of Evolve
+func (a *Annealing) AddObserver(o genetics.Observer) {
+	a.observers = append(a.observers, o)
+}
+
+// stats describe the search for its observers
+func (a *Annealing) stats() genetics.Stats {
+	return genetics.Stats{
+		Generation:    a.Generations,
+		BestFitness:   a.BestFitness,
+		AvgFitness:    float64(a.Current.Fitness),
+		MedianFitness: float64(a.Current.Fitness),
+		WorstFitness:  a.Current.Fitness,
+		Diversity:     genetics.Diversity{Unique: 1},
+		Evaluations:   a.Evaluations,
+		Elapsed:       a.Elapsed(),
+		Seed:          a.Seed,
+		StopReason:    a.StopReason,
+	}
+}
+
+// GetBest will return the best fitness and sequence found so far
+func (a *Annealing) GetBest(g *graph.Graph) (int, *sequence.Sequence) {
+	return a.BestFitness, a.BestGene.Sequence
+}
+
+// SynchronizeRNG will reseed the random number generator from the seed.
+// Checkpoints save the state of the generator, so this is only needed for
+// checkpoints from before they did, which Evolve does itself
+func (a *Annealing) SynchronizeRNG() {
+	a.RNG = genetics.NewRNG(a.Seed)
+}
@@ -0,0 +1,96 @@
+package optimize
+
+import (
+	"context"
+	"math"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/andey-robins/magical/checkpoint"
+	"github.com/andey-robins/magical/parsers/blif"
+	"github.com/andey-robins/magical/sequence"
+)
+
+func TestAnnealingIsDeterministic(t *testing.T) {
+	g := blif.LoadBlifAsGraph("../input/circuits/cm150a_128.blif")
+
+	a := NewAnnealing(50, 5, g, 7, 0, "")
+	b := NewAnnealing(50, 5, g, 7, 0, "")
+	a.Evolve(context.Background(), g)
+	b.Evolve(context.Background(), g)
+
+	if a.Generations != b.Generations || a.BestFitness != b.BestFitness {
+		t.Errorf("expected equal runs, got %d/%d and %d/%d", a.Generations, a.BestFitness, b.Generations, b.BestFitness)
+	}
+
+	fit, seq := a.GetBest(g)
+	if !g.IsValidSequence(seq) {
+		t.Errorf("annealing produced an invalid sequence")
+	}
+	mem, err := g.SimulateSequence(seq)
+	if err != nil || mem.GetMaxUtilization() != fit {
+		t.Errorf("expected best fitness %d to match the best sequence", fit)
+	}
+}
+
+func TestCoolingSchedules(t *testing.T) {
+	tests := []struct {
+		cooling  string
+		k        int
+		expected float64
+	}{
+		{CoolingGeometric, 0, 2},
+		{CoolingGeometric, 2, 2 * 0.9 * 0.9},
+		{CoolingLinear, 5, 1},
+		{CoolingLinear, 20, 0},
+		{CoolingLogarithmic, 0, 2},
+		{CoolingLogarithmic, 3, 2 / (1 + 0.1*math.Log(4))},
+	}
+
+	for _, test := range tests {
+		a := &Annealing{Temperature: 2, Cooling: test.cooling, CoolingRate: 0.9}
+		if temp := a.temperature(test.k); math.Abs(temp-test.expected) > 1e-9 {
+			t.Errorf("%s cooling at step %d: expected %f, got %f", test.cooling, test.k, test.expected, temp)
+		}
+	}
+}
+
+func TestAnnealingStartsFromBestSeed(t *testing.T) {
+	g := blif.LoadBlifAsGraph("../input/circuits/cm150a_128.blif")
+	a := NewAnnealing(10, 1, g, 1, 0, "")
+
+	greedy := g.GreedySequence()
+	a.SeedSequences(g, []*sequence.Sequence{g.SynthesizeRandomValidSequence(5), greedy})
+
+	mem, _ := g.SimulateSequence(greedy)
+	if a.Current.Fitness > mem.GetMaxUtilization() {
+		t.Errorf("expected to start from a fitness of at most %d, got %d", mem.GetMaxUtilization(), a.Current.Fitness)
+	}
+	if a.BestFitness > a.Current.Fitness {
+		t.Errorf("expected the best fitness to include the seed")
+	}
+}
+
+func TestAnnealingResumeMatchesUninterruptedRun(t *testing.T) {
+	g := blif.LoadBlifAsGraph("../input/circuits/cm150a_128.blif")
+	cname := os.TempDir() + "/resume-annealing.json"
+
+	uninterrupted := NewAnnealing(20, 10, g, 3, 0, "")
+	uninterrupted.MaxGenerations = 20
+	uninterrupted.Evolve(context.Background(), g)
+
+	interrupted := NewAnnealing(20, 10, g, 3, 0, "")
+	interrupted.MaxGenerations = 7
+	interrupted.Evolve(context.Background(), g)
+	checkpoint.Save(cname, interrupted)
+
+	resumed := &Annealing{}
+	checkpoint.Load(cname, resumed)
+	resumed.MaxGenerations = 20
+	resumed.Evolve(context.Background(), g)
+
+	if resumed.Generations != uninterrupted.Generations || !reflect.DeepEqual(resumed.Current, uninterrupted.Current) {
+		t.Errorf("expected the resumed search to match the uninterrupted one after %d steps, got %d", uninterrupted.Generations, resumed.Generations)
+	}
+}
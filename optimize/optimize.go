@@ -0,0 +1,91 @@
+package optimize
+
+import (
+	"context"
+
+	"github.com/andey-robins/magical/checkpoint"
+	"github.com/andey-robins/magical/genetics"
+	"github.com/andey-robins/magical/graph"
+	"github.com/andey-robins/magical/sequence"
+)
+
+// An Optimizer searches for a sequence of a graph with the smallest memory
+// footprint. SeedSequences starts the search from known sequences, which
+// are repaired if they aren't valid for the graph. Evolve runs the search
+// until it stops improving, one of its termination rules is met or the
+// context is cancelled, notifying its observers as it goes, and returns an
+// error if the search failed. GetBest returns the best fitness and
+// sequence it found. The metadata describes the run in checkpoints.
+type Optimizer interface {
+	SeedSequences(g *graph.Graph, seqs []*sequence.Sequence)
+	Evolve(ctx context.Context, g *graph.Graph) error
+	AddObserver(o genetics.Observer)
+	GetBest(g *graph.Graph) (int, *sequence.Sequence)
+	SetMetadata(meta checkpoint.Metadata)
+	GetMetadata() checkpoint.Metadata
+}
+
+// Algorithms are the names of the optimizers used in configs and on the
+// command line. The genetic algorithm may also run as an archipelago.
+const (
+	AlgorithmGA        = "ga"
+	AlgorithmAnnealing = "annealing"
+	AlgorithmTabu      = "tabu"
+)
+
+// Algorithms returns the names of every available optimizer
+func Algorithms() []string {
+	return []string{AlgorithmGA, AlgorithmAnnealing, AlgorithmTabu}
+}
+
+var (
+	_ Optimizer = (*genetics.GA)(nil)
+	_ Optimizer = (*genetics.Archipelago)(nil)
+	_ Optimizer = (*Annealing)(nil)
+	_ Optimizer = (*Tabu)(nil)
+)
+
+// bestSeed repairs and evaluates each of `seqs` and returns the best of
+// them, or nil if there are none
+func bestSeed(g *graph.Graph, seqs []*sequence.Sequence, tieBreaker string) *genetics.Gene {
+	var best *genetics.Gene
+	for _, seq := range seqs {
+		gene := newGene(g, g.RepairSequence(seq).GetSequence(), tieBreaker)
+		if best == nil || genetics.Less(gene, best) {
+			best = gene
+		}
+	}
+	return best
+}
+
+// newGene creates and evaluates the gene for `seq`, see evaluateGene
+func newGene(g *graph.Graph, seq []int, tieBreaker string) *genetics.Gene {
+	gene, err := evaluateGene(g, seq, tieBreaker)
+	if err != nil {
+		panic(err)
+	}
+	return gene
+}
+
+// reevaluate evaluates `genes` again with `tieBreaker`, which may have been
+// set after they were first evaluated
+func reevaluate(g *graph.Graph, tieBreaker string, genes ...*genetics.Gene) error {
+	for _, gene := range genes {
+		fitness, secondary, err := genetics.Evaluate(g, gene.Sequence, tieBreaker)
+		if err != nil {
+			return err
+		}
+		gene.Fitness, gene.Secondary = fitness, secondary
+	}
+	return nil
+}
+
+// evaluateGene creates and evaluates the gene for `seq`
+func evaluateGene(g *graph.Graph, seq []int, tieBreaker string) (*genetics.Gene, error) {
+	s := sequence.NewSequence(seq)
+	fitness, secondary, err := genetics.Evaluate(g, s, tieBreaker)
+	if err != nil {
+		return nil, err
+	}
+	return &genetics.Gene{Sequence: s, Fitness: fitness, Secondary: secondary}, nil
+}
@@ -0,0 +1,218 @@
+package optimize
+
+import (
+	"context"
+	"log"
+
+	"github.com/andey-robins/magical/checkpoint"
+	"github.com/andey-robins/magical/genetics"
+	"github.com/andey-robins/magical/graph"
+	"github.com/andey-robins/magical/sequence"
+)
+
+// Tabu is a tabu search over the same moves as local search, see
+// graph.Neighborhood. Each iteration it takes the best move which isn't
+// tabu, even when that makes the sequence worse. A node which has been
+// moved is tabu for Tenure iterations, unless moving it again would find a
+// new best sequence.
+type Tabu struct {
+	Algorithm   string         `json:"algorithm"` // always AlgorithmTabu, identifies checkpoints
+	Current     *genetics.Gene `json:"current"`
+	BestFitness int            `json:"bestFitness"`
+	BestGene    *genetics.Gene `json:"bestGene"`
+	Generations int            `json:"generations"` // the number of iterations taken
+	Seed        int            `json:"seed"`
+	RNG         *genetics.RNG  `json:"rng"`
+	TieBreaker  string         `json:"tieBreaker"` // one of genetics.TieBreakers()
+
+	// Tenure is the number of iterations a moved node stays tabu. Each
+	// iteration evaluates Candidates random moves of the neighborhood, or
+	// the whole neighborhood when it is 0. TabuUntil maps the id of each
+	// node to the first iteration it may be moved again
+	Tenure     int         `json:"tenure"`
+	Candidates int         `json:"candidates"`
+	TabuUntil  map[int]int `json:"tabuUntil"`
+
+	// Termination stops the search early, see genetics.Termination
+	genetics.Termination
+
+	// Metadata describes the run in its checkpoints
+	checkpoint.Metadata
+
+	observers genetics.Observers
+
+	// the number of iterations we will continue searching without improvements
+	Epsilon          int               `json:"epsilon"`
+	CheckpointFreq   int               `json:"checkpointFreq"` // set to 0 to disable checkpoints
+	CheckpointPath   string            `json:"checkpointPath"`
+	CheckpointPolicy checkpoint.Policy `json:"checkpointPolicy"`
+
+	// Workers is the number of goroutines evaluating the neighborhood,
+	// GOMAXPROCS when it is 0. It isn't checkpointed
+	Workers int `json:"-"`
+}
+
+// NewTabu will create a tabu search over `g` which starts from a random
+// valid sequence. It has a tenure of 10 iterations and evaluates the whole
+// neighborhood each iteration; set the exported fields before evolving to
+// change this.
+func NewTabu(e int, g *graph.Graph, seed int, checkpointFreq int, chkpath string) *Tabu {
+	rng := genetics.NewRNG(seed)
+	current := newGene(g, g.SynthesizeRandomValidSequence(rng.Int()).GetSequence(), genetics.TieBreakerNone)
+	best := *current
+
+	return &Tabu{
+		Algorithm:      AlgorithmTabu,
+		Current:        current,
+		BestFitness:    best.Fitness,
+		BestGene:       &best,
+		Seed:           seed,
+		Tenure:         10,
+		TabuUntil:      make(map[int]int),
+		RNG:            rng,
+		Epsilon:        e,
+		CheckpointFreq: checkpointFreq,
+		CheckpointPath: chkpath,
+	}
+}
+
+// Evolve will search until we have gone `epsilon` iterations without
+// improving the best fitness, until one of the rules of t.Termination is met
+// or until `ctx` is cancelled. If a move fails to evaluate the search stops
+// without checkpointing the iteration and the error is returned
+func (t *Tabu) Evolve(ctx context.Context, g *graph.Graph) error {
+	if t.RNG == nil {
+		t.SynchronizeRNG()
+	}
+	t.BeginSearch(g, t.BestFitness)
+	if t.TabuUntil == nil {
+		t.TabuUntil = make(map[int]int)
+	}
+
+	if err := reevaluate(g, t.TieBreaker, t.Current, t.BestGene); err != nil {
+		return err
+	}
+
+	loop := genetics.Loop{
+		Unit:        "iteration",
+		Termination: &t.Termination,
+		Observers:   t.observers,
+		Epsilon:     t.Epsilon,
+		Step: func() (bool, error) {
+			if err := t.step(g); err != nil {
+				return false, err
+			}
+			return t.Improve(t.BestFitness), nil
+		},
+		Stats: t.stats,
+		Best: func() *genetics.Gene {
+			return t.BestGene
+		},
+		Report: func() {
+			log.Printf("Epoch %d: Best fitness: %d Current fitness: %d\n", t.Generations, t.BestFitness, t.Current.Fitness)
+		},
+		Checkpoint:       t,
+		CheckpointFreq:   t.CheckpointFreq,
+		CheckpointPath:   t.CheckpointPath,
+		CheckpointPolicy: t.CheckpointPolicy,
+	}
+	return loop.Run(ctx)
+}
+
+// step takes the best admissible move of the current sequence. If every
+// candidate move is tabu the sequence is left as it is
+//
+// This function is deterministic. Candidates are sampled from t.RNG and
+// evaluated over t.Workers, but the first of equally good moves is always taken
+func (t *Tabu) step(g *graph.Graph) error {
+	seq := t.Current.Sequence.GetSequence()
+	moves := g.Neighborhood(seq)
+	if t.Candidates > 0 && t.Candidates < len(moves) {
+		sampled := make([]graph.Move, t.Candidates)
+		for i, j := range t.RNG.Perm(len(moves))[:t.Candidates] {
+			sampled[i] = moves[j]
+		}
+		moves = sampled
+	}
+
+	neighbors := make([]*genetics.Gene, len(moves))
+	err := genetics.Parallel(t.Workers, len(moves), func(i int) error {
+		var err error
+		neighbors[i], err = evaluateGene(g, moves[i].Apply(seq), t.TieBreaker)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	t.Evaluations += len(moves)
+
+	chosen := -1
+	for i, neighbor := range neighbors {
+		tabu := t.TabuUntil[seq[moves[i].From]] > t.Generations
+		aspiration := genetics.Less(neighbor, t.BestGene)
+		if (!tabu || aspiration) && (chosen < 0 || genetics.Less(neighbor, neighbors[chosen])) {
+			chosen = i
+		}
+	}
+
+	if chosen >= 0 {
+		t.Current = neighbors[chosen]
+		t.TabuUntil[seq[moves[chosen].From]] = t.Generations + 1 + t.Tenure
+		if genetics.Less(t.Current, t.BestGene) {
+			best := *t.Current
+			t.BestFitness = best.Fitness
+			t.BestGene = &best
+		}
+	}
+
+	t.Generations++
+	return nil
+}
+
+// SeedSequences starts the search from the best of `seqs`
+func (t *Tabu) SeedSequences(g *graph.Graph, seqs []*sequence.Sequence) {
+	seed := bestSeed(g, seqs, t.TieBreaker)
+	if seed == nil {
+		return
+	}
+
+	t.Current = seed
+	if genetics.Less(seed, t.BestGene) {
+		best := *seed
+		t.BestFitness = best.Fitness
+		t.BestGene = &best
+	}
+}
+
+// AddObserver adds an observer which is notified of the progress of Evolve
+func (t *Tabu) AddObserver(o genetics.Observer) {
+	t.observers = append(t.observers, o)
+}
+
+// stats describe the search for its observers
+func (t *Tabu) stats() genetics.Stats {
+	return genetics.Stats{
+		Generation:    t.Generations,
+		BestFitness:   t.BestFitness,
+		AvgFitness:    float64(t.Current.Fitness),
+		MedianFitness: float64(t.Current.Fitness),
+		WorstFitness:  t.Current.Fitness,
+		Diversity:     genetics.Diversity{Unique: 1},
+		Evaluations:   t.Evaluations,
+		Elapsed:       t.Elapsed(),
+		Seed:          t.Seed,
+		StopReason:    t.StopReason,
+	}
+}
+
+// GetBest will return the best fitness and sequence found so far
+func (t *Tabu) GetBest(g *graph.Graph) (int, *sequence.Sequence) {
+	return t.BestFitness, t.BestGene.Sequence
+}
+
+// SynchronizeRNG will reseed the random number generator from the seed.
+// Checkpoints save the state of the generator, so this is only needed for
+// checkpoints from before they did, which Evolve does itself
+func (t *Tabu) SynchronizeRNG() {
+	t.RNG = genetics.NewRNG(t.Seed)
+}
@@ -0,0 +1,72 @@
+package optimize
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/andey-robins/magical/checkpoint"
+	"github.com/andey-robins/magical/parsers/blif"
+)
+
+func TestTabuIsDeterministic(t *testing.T) {
+	g := blif.LoadBlifAsGraph("../input/circuits/cm150a_128.blif")
+
+	a := NewTabu(5, g, 7, 0, "")
+	b := NewTabu(5, g, 7, 0, "")
+	a.Candidates, b.Candidates = 30, 30
+	start := a.BestFitness
+	a.Evolve(context.Background(), g)
+	b.Evolve(context.Background(), g)
+
+	if a.Generations != b.Generations || a.BestFitness != b.BestFitness {
+		t.Errorf("expected equal runs, got %d/%d and %d/%d", a.Generations, a.BestFitness, b.Generations, b.BestFitness)
+	}
+	if a.BestFitness > start {
+		t.Errorf("expected best fitness to be at most %d, got %d", start, a.BestFitness)
+	}
+	if _, seq := a.GetBest(g); !g.IsValidSequence(seq) {
+		t.Errorf("tabu search produced an invalid sequence")
+	}
+}
+
+func TestTabuMovedNodeIsTabu(t *testing.T) {
+	g := blif.LoadBlifAsGraph("../input/circuits/cm150a_128.blif")
+
+	tabu := NewTabu(1, g, 3, 0, "")
+	tabu.Tenure = 4
+	tabu.step(g)
+
+	if len(tabu.TabuUntil) != 1 {
+		t.Fatalf("expected exactly the moved node to be tabu, got %v", tabu.TabuUntil)
+	}
+	for id, until := range tabu.TabuUntil {
+		if until != 5 {
+			t.Errorf("expected node %d to be tabu until iteration 5, got %d", id, until)
+		}
+	}
+}
+
+func TestTabuResumeMatchesUninterruptedRun(t *testing.T) {
+	g := blif.LoadBlifAsGraph("../input/circuits/cm150a_128.blif")
+	cname := os.TempDir() + "/resume-tabu.json"
+
+	uninterrupted := NewTabu(10, g, 3, 0, "")
+	uninterrupted.Candidates, uninterrupted.MaxGenerations = 20, 20
+	uninterrupted.Evolve(context.Background(), g)
+
+	interrupted := NewTabu(10, g, 3, 0, "")
+	interrupted.Candidates, interrupted.MaxGenerations = 20, 7
+	interrupted.Evolve(context.Background(), g)
+	checkpoint.Save(cname, interrupted)
+
+	resumed := &Tabu{}
+	checkpoint.Load(cname, resumed)
+	resumed.MaxGenerations = 20
+	resumed.Evolve(context.Background(), g)
+
+	if resumed.Generations != uninterrupted.Generations || !reflect.DeepEqual(resumed.Current, uninterrupted.Current) {
+		t.Errorf("expected the resumed search to match the uninterrupted one after %d iterations, got %d", uninterrupted.Generations, resumed.Generations)
+	}
+}
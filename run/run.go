@@ -1,6 +1,8 @@
 package run
 
 import (
+	"context"
+
 	"github.com/andey-robins/magical/genetics"
 	"github.com/andey-robins/magical/graph"
 )
@@ -14,6 +16,6 @@ func NewRun(pop *genetics.GA, g *graph.Graph) *Run {
 	return &Run{pop, g}
 }
 
-func (r *Run) Evaluate() {
-	r.GA.Evolve(r.Graph)
+func (r *Run) Evaluate() error {
+	return r.GA.Evolve(context.Background(), r.Graph)
 }
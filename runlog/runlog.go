@@ -0,0 +1,154 @@
+package runlog
+
+// A run log records the progress of an optimizer one generation per line
+// so convergence curves can be plotted without scraping the verbose log.
+// It is written as JSON lines, or as CSV when the file name ends in .csv,
+// and ends with a summary of the whole run.
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/andey-robins/magical/genetics"
+)
+
+// Record types mark each generation and the summary at the end of the run
+const (
+	TypeGeneration = "generation"
+	TypeSummary    = "summary"
+)
+
+// A Record is one line of the run log. The diversity is described by
+// genetics.Diversity, Elapsed is in seconds and StopReason is only set on
+// the summary
+type Record struct {
+	Type          string  `json:"type"`
+	Generation    int     `json:"generation"`
+	BestFitness   int     `json:"best"`
+	AvgFitness    float64 `json:"average"`
+	MedianFitness float64 `json:"median"`
+	WorstFitness  int     `json:"worst"`
+	Unique        int     `json:"unique"`
+	Distance      float64 `json:"distance"`
+	Entropy       float64 `json:"entropy"`
+	Evaluations   int     `json:"evaluations"`
+	CacheHits     int     `json:"cacheHits"`
+	CacheMisses   int     `json:"cacheMisses"`
+	Elapsed       float64 `json:"elapsed"`
+	Seed          int     `json:"seed"`
+	StopReason    string  `json:"stopReason,omitempty"`
+}
+
+// columns are the CSV header, in the order of the fields of Record
+var columns = []string{"type", "generation", "best", "average", "median", "worst", "unique", "distance", "entropy", "evaluations", "cacheHits", "cacheMisses", "elapsed", "seed", "stopReason"}
+
+func newRecord(kind string, stats genetics.Stats) Record {
+	return Record{
+		Type:          kind,
+		Generation:    stats.Generation,
+		BestFitness:   stats.BestFitness,
+		AvgFitness:    stats.AvgFitness,
+		MedianFitness: stats.MedianFitness,
+		WorstFitness:  stats.WorstFitness,
+		Unique:        stats.Diversity.Unique,
+		Distance:      stats.Diversity.Distance,
+		Entropy:       stats.Diversity.Entropy,
+		Evaluations:   stats.Evaluations,
+		CacheHits:     stats.CacheHits,
+		CacheMisses:   stats.CacheMisses,
+		Elapsed:       stats.Elapsed.Seconds(),
+		Seed:          stats.Seed,
+		StopReason:    stats.StopReason,
+	}
+}
+
+func (r Record) row() []string {
+	float := func(f float64) string {
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	return []string{
+		r.Type,
+		strconv.Itoa(r.Generation),
+		strconv.Itoa(r.BestFitness),
+		float(r.AvgFitness),
+		float(r.MedianFitness),
+		strconv.Itoa(r.WorstFitness),
+		strconv.Itoa(r.Unique),
+		float(r.Distance),
+		float(r.Entropy),
+		strconv.Itoa(r.Evaluations),
+		strconv.Itoa(r.CacheHits),
+		strconv.Itoa(r.CacheMisses),
+		float(r.Elapsed),
+		strconv.Itoa(r.Seed),
+		r.StopReason,
+	}
+}
+
+// A Logger is an observer which writes a record for every generation and
+// the summary when the search finishes. Write errors don't interrupt the
+// search; the first one is returned by Close
+type Logger struct {
+	genetics.BaseObserver
+
+	file *os.File
+	csv  *csv.Writer
+	json *json.Encoder
+	err  error
+}
+
+// Create creates or truncates the run log at `path`, creating its
+// directory if needed
+func Create(path string) (*Logger, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Logger{file: file}
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		l.csv = csv.NewWriter(file)
+		l.err = l.csv.Write(columns)
+	} else {
+		l.json = json.NewEncoder(file)
+	}
+	return l, nil
+}
+
+func (l *Logger) OnGeneration(stats genetics.Stats) {
+	l.write(newRecord(TypeGeneration, stats))
+}
+
+func (l *Logger) OnFinish(stats genetics.Stats) {
+	l.write(newRecord(TypeSummary, stats))
+}
+
+func (l *Logger) write(r Record) {
+	if l.err != nil {
+		return
+	}
+	if l.csv != nil {
+		l.err = l.csv.Write(r.row())
+		l.csv.Flush()
+		if l.err == nil {
+			l.err = l.csv.Error()
+		}
+		return
+	}
+	l.err = l.json.Encode(r)
+}
+
+// Close closes the run log and returns the first error writing it
+func (l *Logger) Close() error {
+	if err := l.file.Close(); l.err == nil {
+		l.err = err
+	}
+	return l.err
+}
@@ -0,0 +1,88 @@
+package runlog
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/andey-robins/magical/genetics"
+)
+
+func writeLog(t *testing.T, path string) {
+	l, err := Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.OnGeneration(genetics.Stats{Generation: 1, BestFitness: 10, AvgFitness: 12.5, WorstFitness: 15, Elapsed: time.Second, Seed: 3})
+	l.OnGeneration(genetics.Stats{Generation: 2, BestFitness: 9, AvgFitness: 11, WorstFitness: 14, Elapsed: 2 * time.Second, Seed: 3})
+	l.OnFinish(genetics.Stats{Generation: 2, BestFitness: 9, Seed: 3, StopReason: genetics.StopEpsilon})
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestJSONLines(t *testing.T) {
+	path := t.TempDir() + "/run.jsonl"
+	writeLog(t, path)
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	records := make([]Record, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, r)
+	}
+
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(records))
+	}
+	if r := records[1]; r.Type != TypeGeneration || r.BestFitness != 9 || r.Elapsed != 2 || r.Seed != 3 {
+		t.Errorf("unexpected generation record %+v", r)
+	}
+	if r := records[2]; r.Type != TypeSummary || r.StopReason != genetics.StopEpsilon {
+		t.Errorf("unexpected summary record %+v", r)
+	}
+}
+
+func TestCSV(t *testing.T) {
+	path := t.TempDir() + "/run.csv"
+	writeLog(t, path)
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rows) != 4 {
+		t.Fatalf("expected a header and 3 rows, got %d rows", len(rows))
+	}
+	if len(rows[0]) != len(columns) || rows[0][2] != "best" {
+		t.Errorf("unexpected header %v", rows[0])
+	}
+	expected := []string{"generation", "1", "10", "12.5", "0", "15", "0", "0", "0", "0", "0", "0", "1", "3", ""}
+	for i, value := range expected {
+		if rows[1][i] != value {
+			t.Errorf("expected %s to be %s, got %s", columns[i], value, rows[1][i])
+		}
+	}
+	if rows[3][0] != TypeSummary || rows[3][len(columns)-1] != genetics.StopEpsilon {
+		t.Errorf("unexpected summary row %v", rows[3])
+	}
+}
@@ -38,3 +38,19 @@ func ValidateRangeFloat(min, max float64, value interface{}) Rule {
 		return nil
 	}
 }
+
+// ValidateOneOf will assert that the value is the empty string or one
+// of the options
+func ValidateOneOf(key, value string, options []string) Rule {
+	return func() error {
+		if value == "" {
+			return nil
+		}
+		for _, option := range options {
+			if value == option {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s must be one of %v", key, options)
+	}
+}